@@ -0,0 +1,67 @@
+package convert
+
+import (
+	"slices"
+	"strings"
+)
+
+// A Validator is a Converter that can check its own definition for problems
+// such as missing data or a zero factor.
+type Validator interface {
+	Validate() error
+}
+
+// AuditStore checks the Converters in the store and returns the problems it
+// finds, sorted by unit name and then by category: Converters that fail their
+// own validation, Converters without a base UOM, units whose name is defined
+// more than once in the files read by AddFromFiles or LoadDefaults, so that
+// only the last definition is kept, and categories whose units do not all
+// share the same base UOM. It returns nil if no problems are found.
+func AuditStore() []error {
+	return store.Audit()
+}
+
+// Audit checks the Converters in the store and returns the problems it finds.
+// See AuditStore for details.
+func (s *Store) Audit() []error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	bases := make(map[string][]string)
+	for _, k := range keys {
+		c := s.data[k]
+		if v, ok := c.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		} else if c.BaseUOM() == "" {
+			errs = append(errs, Error(ErrMissingData, c.Name()+": base UOM"))
+		}
+		if err, ok := s.clashes[k]; ok {
+			errs = append(errs, err)
+		}
+		if !slices.Contains(bases[c.Category()], c.BaseUOM()) {
+			bases[c.Category()] = append(bases[c.Category()], c.BaseUOM())
+		}
+	}
+
+	categories := make([]string, 0, len(bases))
+	for c := range bases {
+		categories = append(categories, c)
+	}
+	slices.Sort(categories)
+	for _, c := range categories {
+		if len(bases[c]) > 1 {
+			slices.Sort(bases[c])
+			errs = append(errs, Error(ErrInconsistentCategory, c+": "+strings.Join(bases[c], ", ")))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+package convert
+
+// ToBase converts val from the unit specified by from to the base UOM of its
+// category and returns the converted value and the name of the base UOM. Units
+// that can convert to their base UOM themselves, such as linear units, do so;
+// other units are converted to the base UOM as registered in the store. As in
+// ToValue, ErrInvalidValue is returned if the result is NaN or infinite, e.g.
+// for 0 miles per gallon.
+func ToBase(val float64, from string) (float64, string, error) {
+	return store.ToBase(val, from)
+}
+
+// ToBase converts val from the unit specified by from to the base UOM of its
+// category. See the package-level ToBase for details.
+func (s *Store) ToBase(val float64, from string) (float64, string, error) {
+	f, ok := s.Get(from)
+	if !ok {
+		return 0, "", Error(ErrUnknownUnit, from)
+	}
+
+	var v float64
+	if bc, ok := f.(BaseConverter); ok {
+		v = bc.ConvertToBase(val)
+	} else {
+		b, ok := s.Get(f.BaseUOM())
+		if !ok {
+			return 0, "", Error(ErrUnknownUnit, f.BaseUOM())
+		}
+		var err error
+		if v, err = f.Convert(val, b); err != nil {
+			return 0, "", err
+		}
+	}
+	v, err := checkResult(v, val, f.Name(), f.BaseUOM())
+	if err != nil {
+		return 0, "", err
+	}
+	return v, f.BaseUOM(), nil
+}
+
+// FromBase converts baseVal, a value in the base UOM of the unit specified by
+// to, to that unit. It is the inverse of ToBase. For units that cannot convert
+// from their base UOM themselves, the base UOM must be registered in the store
+// as a unit with the same base UOM as to; ErrIncompatibleUnits is returned
+// otherwise. ErrInvalidValue is returned if the result is NaN or infinite.
+func FromBase(baseVal float64, to string) (float64, error) {
+	return store.FromBase(baseVal, to)
+}
+
+// FromBase converts baseVal, a value in the base UOM of the unit specified by
+// to, to that unit. See the package-level FromBase for details.
+func (s *Store) FromBase(baseVal float64, to string) (float64, error) {
+	t, ok := s.Get(to)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, to)
+	}
+
+	var v float64
+	var err error
+	if bc, ok := t.(BaseConverter); ok {
+		v, err = fromBase(bc, baseVal)
+	} else {
+		b, ok := s.Get(t.BaseUOM())
+		if !ok {
+			return 0, Error(ErrUnknownUnit, t.BaseUOM())
+		}
+		if b.BaseUOM() != t.BaseUOM() {
+			return 0, ErrIncompatibleUnits
+		}
+		v, err = b.Convert(baseVal, t)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return checkResult(v, baseVal, t.BaseUOM(), t.Name())
+}
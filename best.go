@@ -0,0 +1,56 @@
+package convert
+
+import "math"
+
+// BestUnit returns the unit in category in which baseVal, a value in the base
+// UOM of the category, reads most naturally, together with baseVal converted
+// to that unit. The best unit is the one whose converted value lies in [1,
+// 1000), or is closest to that range in orders of magnitude; among several
+// such units the one with the smallest value wins, so 1500 meters selects 1.5
+// kilometers and 0.003 meters selects 3 millimeters. A zero baseVal selects the
+// base UOM if it is registered in the category.
+func BestUnit(baseVal float64, category string) (Uom, float64, error) {
+	return store.BestUnit(baseVal, category)
+}
+
+// BestUnit returns the unit in category in which baseVal reads most naturally.
+// See the package-level BestUnit for details.
+func (s *Store) BestUnit(baseVal float64, category string) (Uom, float64, error) {
+	units := s.UnitsByCategory(category)
+	if len(units) == 0 {
+		return Uom{}, 0, Error(ErrMissingData, "no units in category "+category)
+	}
+
+	// score returns how many orders of magnitude v is away from [1, 1000).
+	score := func(u Uom, v float64) float64 {
+		a := math.Abs(v)
+		switch {
+		case a == 0:
+			if u.Name == u.BaseUOM {
+				return 0
+			}
+			return 1
+		case a < 1:
+			return -math.Log10(a)
+		case a >= 1000:
+			return math.Log10(a / 1000)
+		}
+		return 0
+	}
+
+	best, bestVal, bestScore := -1, 0.0, math.Inf(1)
+	for i, u := range units {
+		v, err := s.FromBase(baseVal, u.Name)
+		if err != nil {
+			continue
+		}
+		sc := score(u, v)
+		if best < 0 || sc < bestScore || (sc == bestScore && math.Abs(v) < math.Abs(bestVal)) {
+			best, bestVal, bestScore = i, v, sc
+		}
+	}
+	if best < 0 {
+		return Uom{}, 0, Error(ErrIncompatibleUnits, "no unit in category "+category+" converts from its base UOM")
+	}
+	return units[best], bestVal, nil
+}
@@ -0,0 +1,178 @@
+package convert
+
+import (
+	"math"
+	"strings"
+)
+
+// RatioCategory is the category of the dimensionless ratio units returned by
+// Ratios.
+const RatioCategory = "Ratio"
+
+// Ratios returns Converters for the common dimensionless ratios: fraction,
+// percent, per-mille, ppm and ppb, all against the base UOM "fraction", so that
+// 1 percent is 0.01 fraction. The offset of every ratio is zero.
+func Ratios() []Converter {
+	ratio := func(name, symbol string, factor float64) Converter {
+		return MustLinearConverter(name, symbol, "fraction", RatioCategory, factor, 0)
+	}
+	return []Converter{
+		ratio("fraction", "", 1),
+		ratio("percent", "%", 1e-2),
+		ratio("per-mille", "‰", 1e-3),
+		ratio("ppm", "ppm", 1e-6),
+		ratio("ppb", "ppb", 1e-9),
+	}
+}
+
+// RegisterRatios adds/updates the Converters returned by Ratios to/in the
+// store. Like RegisterAll, it returns an error and leaves the store unchanged
+// if the units do not fit; see SetMaxUnits.
+func RegisterRatios() error {
+	return store.RegisterAll(Ratios())
+}
+
+// AngleCategory is the category of the angle units returned by Angles. It
+// matches the category of the bundled angle units.
+const AngleCategory = "Angle"
+
+// fullTurns holds the size of a full turn in the base UOMs used for angles.
+var fullTurns = map[string]float64{
+	"degree":  360,
+	"radian":  2 * math.Pi,
+	"gradian": 400,
+	"turn":    1,
+}
+
+// Angles returns Converters for the common angle units: degree, radian,
+// gradian and turn, all against the base UOM "degree", so that 1 turn is 360
+// degrees. The offset of every angle is zero.
+func Angles() []Converter {
+	angle := func(name, symbol string, factor float64) Converter {
+		return MustLinearConverter(name, symbol, "degree", AngleCategory, factor, 0)
+	}
+	return []Converter{
+		angle("degree", "°", 1),
+		angle("radian", "rad", 180/math.Pi),
+		angle("gradian", "grad", 0.9),
+		angle("turn", "tr", 360),
+	}
+}
+
+// RegisterAngles adds/updates the Converters returned by Angles to/in the
+// store. Like RegisterAll, it returns an error and leaves the store unchanged
+// if the units do not fit; see SetMaxUnits.
+func RegisterAngles() error {
+	return store.RegisterAll(Angles())
+}
+
+// ToValueNormalized converts val from the unit specified by from to the unit
+// specified by to like ToValue. If the units are angles, i.e. in AngleCategory
+// with a base UOM of degree, radian, gradian or turn, the result is wrapped
+// into the principal range of the target unit, e.g. [0, 360) for degrees and
+// [0, 2π) for radians, so that 450 degrees become 90 degrees. Other units are
+// converted as by ToValue.
+func ToValueNormalized(val float64, from, to string) (float64, error) {
+	return store.ToValueNormalized(val, from, to)
+}
+
+// ToValueNormalized converts val from the unit specified by from to the unit
+// specified by to, wrapping angles into the principal range of the target
+// unit. See the package-level ToValueNormalized for details.
+func (s *Store) ToValueNormalized(val float64, from, to string) (float64, error) {
+	v, err := s.ToValue(val, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	t, _ := s.Get(to)
+	turn, ok := fullTurns[strings.ToLower(t.BaseUOM())]
+	if !ok || !strings.EqualFold(t.Category(), AngleCategory) {
+		return v, nil
+	}
+	start, err := s.FromBase(0, to)
+	if err != nil {
+		return 0, err
+	}
+	end, err := s.FromBase(turn, to)
+	if err != nil {
+		return 0, err
+	}
+
+	period := math.Abs(end - start)
+	v = math.Mod(v, period)
+	if v < 0 {
+		v += period
+	}
+	if v >= period {
+		// v was a tiny negative value that rounded up to the period.
+		v = 0
+	}
+	return v, nil
+}
+
+// DataCategory is the category of the data storage units returned by
+// DataStorage. It matches the category of the bundled data units.
+const DataCategory = "Computer"
+
+// DataStorage returns Converters for the units of digital information against
+// the base UOM "byte": bit and byte, and the byte multiples with decimal
+// prefixes (kB, MB, GB, TB, PB and EB, powers of 1000) and binary prefixes
+// (KiB, MiB, GiB, TiB, PiB and EiB, powers of 1024). The multiples are named
+// by their symbols, so that 1 MB is 1000000 byte and 1 MiB is 1048576 byte.
+// Note that the bundled data uses the binary convention instead: its kilobyte,
+// with the symbol "kB", is 1024 byte.
+func DataStorage() []Converter {
+	data := func(name, symbol string, factor float64) Converter {
+		return MustLinearConverter(name, symbol, "byte", DataCategory, factor, 0)
+	}
+	cs := []Converter{
+		data("bit", "bit", 0.125),
+		data("byte", "B", 1),
+	}
+	for i, prefix := range []string{"k", "M", "G", "T", "P", "E"} {
+		cs = append(cs, data(prefix+"B", prefix+"B", math.Pow(1000, float64(i+1))))
+	}
+	for i, prefix := range []string{"Ki", "Mi", "Gi", "Ti", "Pi", "Ei"} {
+		cs = append(cs, data(prefix+"B", prefix+"B", math.Pow(1024, float64(i+1))))
+	}
+	return cs
+}
+
+// RegisterDataStorage adds/updates the Converters returned by DataStorage
+// to/in the store, except those that duplicate a linear unit already in the
+// store, i.e. one with the same category, base UOM and factor under any name.
+// After LoadDefaults, whose kilobyte to exabyte are the binary multiples, only
+// the decimal multiples kB to EB are added. Like RegisterAll, it returns an
+// error and leaves the store unchanged if the units do not fit; see
+// SetMaxUnits.
+func RegisterDataStorage() error {
+	return store.registerMissing(DataStorage())
+}
+
+// registerMissing adds the linear units in cs to the store with RegisterAll,
+// skipping those for which the store already holds a linear unit with the
+// same category, base UOM and factor.
+func (s *Store) registerMissing(cs []Converter) error {
+	type definition struct {
+		category, baseuom string
+		factor            float64
+	}
+	have := make(map[definition]bool)
+	for _, c := range s.FilterConverters(func(c Converter) bool {
+		_, ok := c.(linearConverter)
+		return ok
+	}) {
+		lc := c.(linearConverter)
+		have[definition{lc.category, lc.baseuom, lc.factor}] = true
+	}
+
+	var missing []Converter
+	for _, c := range cs {
+		if lc, ok := c.(linearConverter); ok && have[definition{lc.category, lc.baseuom, lc.factor}] {
+			continue
+		}
+		missing = append(missing, c)
+	}
+	return s.RegisterAll(missing)
+}
@@ -0,0 +1,59 @@
+package convert
+
+// maxCachedPairs bounds the number of unit pairs cached by linearPair. The
+// cache is cleared when it is full, so that a store converting between many
+// pairs does not keep n² entries.
+const maxCachedPairs = 1024
+
+// linearUnits holds the two compatible linear units of a cached conversion.
+type linearUnits struct {
+	from, to linearConverter
+}
+
+// linearPair returns the units specified by from and to if both are
+// compatible linear units, looking them up and checking them on first use and
+// caching them afterwards. The conversion itself is left to ConvertValue, so
+// that cached and uncached conversions compute identical results. The cache is
+// cleared by invalidate whenever a Converter is added or removed.
+func (s *Store) linearPair(from, to string) (linearUnits, bool) {
+	pair := [2]string{unitKey(from), unitKey(to)}
+
+	// hold the read lock while filling the cache, so that Converters replaced
+	// in the meantime are never cached.
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.cacheMu.Lock()
+	u, ok := s.linear[pair]
+	s.cacheMu.Unlock()
+	if ok {
+		return u, true
+	}
+
+	f, ok := s.data[pair[0]].(linearConverter)
+	if !ok {
+		return linearUnits{}, false
+	}
+	t, ok := s.data[pair[1]].(linearConverter)
+	if !ok || f.baseuom != t.baseuom || f.category != t.category {
+		return linearUnits{}, false
+	}
+
+	u = linearUnits{from: f, to: t}
+	s.cacheMu.Lock()
+	if s.linear == nil || len(s.linear) >= maxCachedPairs {
+		s.linear = make(map[[2]string]linearUnits)
+	}
+	s.linear[pair] = u
+	s.cacheMu.Unlock()
+	return u, true
+}
+
+// invalidate clears the caches of linearPair and Categories. The caller must
+// hold the write lock.
+func (s *Store) invalidate() {
+	s.cacheMu.Lock()
+	s.linear = nil
+	s.categories = nil
+	s.cacheMu.Unlock()
+}
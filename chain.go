@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ToValueChain converts val through each unit in units in turn, e.g. from
+// inches to feet and then from feet to meters, and returns the value in the
+// last unit. At least two units are required. An error from any step is
+// returned annotated with the step that failed.
+func ToValueChain(val float64, units ...string) (float64, error) {
+	return store.ToValueChain(val, units...)
+}
+
+// ToValueChain converts val through each unit in units in turn. See the
+// package-level ToValueChain for details.
+func (s *Store) ToValueChain(val float64, units ...string) (float64, error) {
+	if len(units) < 2 {
+		return 0, Error(ErrMissingData, "at least two units are required")
+	}
+
+	for i := 1; i < len(units); i++ {
+		v, err := s.ToValue(val, units[i-1], units[i])
+		if err != nil {
+			return 0, Error(err, "step "+strconv.Itoa(i)+" ("+units[i-1]+" -> "+units[i]+")")
+		}
+		val = v
+	}
+	return val, nil
+}
+
+// ToValues1toN converts val from the unit specified by from to each unit in
+// tos and returns the results keyed by the target names as given. It fails
+// fast: if any target is unknown or cannot be converted to, no results are
+// returned and the error is annotated with that target.
+func ToValues1toN(val float64, from string, tos []string) (map[string]float64, error) {
+	return store.ToValues1toN(val, from, tos)
+}
+
+// ToValues1toN converts val from the unit specified by from to each unit in
+// tos. See the package-level ToValues1toN for details.
+func (s *Store) ToValues1toN(val float64, from string, tos []string) (map[string]float64, error) {
+	results := make(map[string]float64, len(tos))
+	for _, to := range tos {
+		v, err := s.ToValue(val, from, to)
+		if err != nil {
+			return nil, Error(err, to)
+		}
+		results[to] = v
+	}
+	return results, nil
+}
+
+// NormalizeTo converts each value in values from the unit specified by the
+// corresponding entry of froms to the unit specified by to, e.g. to bring
+// readings taken in different units into one unit, and returns the results in
+// the same order. values and froms must have the same length; ErrMalformedData
+// is returned otherwise. Each unit is looked up once however often it occurs.
+// An error for any value is returned annotated with its index and unit, and no
+// results are returned.
+func NormalizeTo(values []float64, froms []string, to string) ([]float64, error) {
+	return store.NormalizeTo(values, froms, to)
+}
+
+// NormalizeTo converts each value in values from the corresponding unit in
+// froms to the unit specified by to. See the package-level NormalizeTo for
+// details.
+func (s *Store) NormalizeTo(values []float64, froms []string, to string) ([]float64, error) {
+	if len(values) != len(froms) {
+		return nil, Error(ErrMalformedData, strconv.Itoa(len(values))+" values but "+strconv.Itoa(len(froms))+" units")
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return nil, Error(ErrUnknownUnit, to)
+	}
+
+	resolved := make(map[string]Converter)
+	results := make([]float64, len(values))
+	for i, val := range values {
+		from := froms[i]
+		f, ok := resolved[from]
+		if !ok {
+			if f, ok = s.Get(from); !ok {
+				return nil, Error(ErrUnknownUnit, "value "+strconv.Itoa(i)+" ("+from+")")
+			}
+			resolved[from] = f
+		}
+		v, err := ConvertValue(val, f, t)
+		if errors.Is(err, ErrIncompatibleUnits) {
+			if pv, ok := s.convertPairwise(val, f, t); ok {
+				v, err = pv, nil
+			}
+		}
+		if err != nil {
+			return nil, Error(err, "value "+strconv.Itoa(i)+" ("+from+")")
+		}
+		results[i] = v
+	}
+	return results, nil
+}
@@ -0,0 +1,69 @@
+package convert
+
+// A ChangeOp is the kind of change to a store reported in a ChangeEvent.
+type ChangeOp int
+
+const (
+	ChangeAdd    ChangeOp = iota // a Converter was added or replaced.
+	ChangeRemove                 // a Converter was removed.
+	ChangeClear                  // all Converters were removed.
+)
+
+// String returns the name of op.
+func (op ChangeOp) String() string {
+	switch op {
+	case ChangeAdd:
+		return "add"
+	case ChangeRemove:
+		return "remove"
+	case ChangeClear:
+		return "clear"
+	}
+	return "unknown"
+}
+
+// A ChangeEvent describes a change to a store. Name is the name of the unit
+// that was added or removed; it is empty for ChangeClear.
+type ChangeEvent struct {
+	Op   ChangeOp
+	Name string
+}
+
+// OnChange registers fn to be called for every change to the store, once per
+// Converter added or removed. fn is called after the change is complete,
+// without holding any lock of the store, so it may use the package. It is
+// called from the goroutine that made the change, so it must be safe for
+// concurrent use if the store is changed concurrently.
+func OnChange(fn func(ChangeEvent)) {
+	store.OnChange(fn)
+}
+
+// OnChange registers fn to be called for every change to the store. See the
+// package-level OnChange for details.
+func (s *Store) OnChange(fn func(ChangeEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// record queues ev for delivery to the listeners once the store is unlocked
+// by unlock. The caller must hold the lock.
+func (s *Store) record(ev ChangeEvent) {
+	if len(s.listeners) > 0 {
+		s.pending = append(s.pending, ev)
+	}
+}
+
+// unlock releases the write lock and then delivers the events recorded while
+// it was held.
+func (s *Store) unlock() {
+	events, listeners := s.pending, s.listeners
+	s.pending = nil
+	s.mu.Unlock()
+
+	for _, ev := range events {
+		for _, fn := range listeners {
+			fn(ev)
+		}
+	}
+}
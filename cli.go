@@ -0,0 +1,78 @@
+package convert
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RunCLI runs a command line conversion with args, the command line arguments
+// without the program name, and returns the exit code: 0 on success, 1 if the
+// conversion fails and 2 if args are invalid. args are of the form
+//
+//	[-json] [-decimals n] [--] value from to
+//
+// where "--" is needed before negative values. The result is written to
+// stdout as the formatted value followed by the symbol, or the name, of the
+// unit converted to; with -json, the response of ToJson, including the
+// formatted result, is written instead. Errors are written to stderr. RunCLI
+// uses the units of the default store, so callers register the units first,
+// e.g. with LoadDefaults.
+func RunCLI(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "write the result as JSON")
+	decimals := fs.Int("decimals", -1, "round the result to `n` decimals; negative for full precision")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: convert [-json] [-decimals n] [--] value from to")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return 2
+	}
+
+	from, to := fs.Arg(1), fs.Arg(2)
+	val, err := strconv.ParseFloat(fs.Arg(0), 64)
+	if err != nil {
+		fmt.Fprintln(stderr, "convert:", Error(ErrMalformedData, "value is not a number: "+fs.Arg(0)))
+		return 2
+	}
+
+	if *asJSON {
+		resp := store.newResponse(val, from, to)
+		if resp.Ok {
+			resp.Formatted = FormatResult(resp.Result, *decimals)
+		}
+		b, err := json.Marshal(resp)
+		if err != nil {
+			fmt.Fprintln(stderr, "convert:", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(b))
+		if !resp.Ok {
+			return 1
+		}
+		return 0
+	}
+
+	result, err := ToValue(val, from, to)
+	if err != nil {
+		fmt.Fprintln(stderr, "convert:", err)
+		return 1
+	}
+	unit := to
+	if c, ok := store.Get(to); ok {
+		unit = c.Name()
+		if c.Symbol() != "" {
+			unit = c.Symbol()
+		}
+	}
+	fmt.Fprintln(stdout, FormatResult(result, *decimals), unit)
+	return 0
+}
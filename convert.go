@@ -19,6 +19,10 @@
 //
 //	categories := convert.Categories()
 //
+// To get the description of a category, use the CategoryDescription function:
+//
+//	desc := convert.CategoryDescription("Temperature")
+//
 // To add/update a Converter to/in the store, use the AddConverter function:
 //
 //	c := &MyConverter{...}
@@ -32,30 +36,55 @@
 //
 //	convert.Clear()
 //
+// The functions above operate on a default store shared by the whole program.
+// To keep a separate set of units, create a Store and use its methods:
+//
+//	s := convert.NewStore()
+//	s.Add(c)
+//	val, err := s.ToValue(value, from, to)
+//
+// The store starts empty. To add/update the standard units bundled with the
+// package, use the LoadDefaults function:
+//
+//	err := convert.LoadDefaults()
+//
 // To add/update Converters from files, use the AddFromFiles function:
 //
 //	convert.AddFromFiles(myConverterReader, "path/to/converters/*.json")
 //
+// To write all linear Converters in the store to a JSON file that can be read
+// back with AddFromFiles, use the ExportJSON function:
+//
+//	err := convert.ExportJSON(w)
+//
 // The Converter interface defines the methods that must be implemented by a
 // unit of measurement (UOM) converter. The ToValue and ToJson functions use
 // this interface to perform the conversion.
 package convert
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"path/filepath"
-	"slices"
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
-	"sync"
 )
 
 var (
-	ErrUnknownUnit       = errors.New("unknown unit")
-	ErrCategoryMismatch  = errors.New("units are not in the same category")
-	ErrMissingData       = errors.New("missing data")
-	ErrZeroNotAllowed    = errors.New("zero not allowed")
-	ErrIncompatibleUnits = errors.New("incompatible units")
+	ErrUnknownUnit          = errors.New("unknown unit")
+	ErrCategoryMismatch     = errors.New("units are not in the same category")
+	ErrMissingData          = errors.New("missing data")
+	ErrZeroNotAllowed       = errors.New("zero not allowed")
+	ErrIncompatibleUnits    = errors.New("incompatible units")
+	ErrNotExportable        = errors.New("converter cannot be exported")
+	ErrDuplicateUnit        = errors.New("unit already defined in another category")
+	ErrInconsistentCategory = errors.New("category has mixed base UOMs")
+	ErrMalformedData        = errors.New("malformed data")
+	ErrOutOfDomain          = errors.New("value outside the domain of the unit")
+	ErrInvalidValue         = errors.New("value is not a finite number")
+	ErrStoreFull            = errors.New("store is full")
 )
 
 // A Converter represents a unit of measurement (UOM) that can be converted to
@@ -68,184 +97,506 @@ type Converter interface {
 	BaseUOM() string
 }
 
+// A BaseConverter is a Converter that converts values to and from the base
+// UOM of its category itself. Converters of different types, including types
+// defined outside this package, can be converted into each other through the
+// base UOM by ConvertValue and ToValue when both are BaseConverters with the
+// same base UOM and category.
+type BaseConverter interface {
+	ConvertToBase(val float64) float64
+	ConvertFromBase(val float64) float64
+}
+
+// A Describer is a Converter that carries descriptive text about its unit and
+// the category it belongs to.
+type Describer interface {
+	Description() string
+	CategoryDescription() string
+}
+
+// An Exacter is a Converter that knows whether its conversion to the base UOM
+// is exact by definition, like the inch at 25.4 millimeters, or a rounded
+// approximation.
+type Exacter interface {
+	Exact() bool
+}
+
+// isExact reports whether c is an Exacter whose conversion is exact.
+// Converters that are not Exacters are not known to be exact.
+func isExact(c Converter) bool {
+	e, ok := c.(Exacter)
+	return ok && e.Exact()
+}
+
+// OnConvert, if not nil, is called after every conversion made by ToValue or
+// ToValueSameBase, with the unit names as passed and the resulting error, if
+// any. It is called without holding any lock of the store, so it may use the
+// package. It must be set before conversions are made concurrently and must be
+// safe for concurrent use.
+var OnConvert func(from, to string, err error)
+
 // ToValue converts val from the unit specified by from to the unit
 // specified by to. It returns the converted value and nil, or 0 and an error.
+// If the units cannot be converted through a common base UOM, ToValue falls
+// back to the conversions registered with AddPairwise. ErrInvalidValue is
+// returned if val or the result is NaN or infinite, e.g. because the
+// conversion overflows or divides by zero. A zero result is always a positive
+// zero.
 func ToValue(val float64, from, to string) (float64, error) {
-	f, ok := store.get(strings.ToLower(from))
-	if !ok {
-		return 0, Error(ErrUnknownUnit, from)
+	return store.ToValue(val, from, to)
+}
+
+// ConvertValue converts val from the unit of from to the unit of to. Neither
+// Converter needs to be registered in a store. It returns the converted value
+// and nil, or 0 and an error; ErrMissingData if either Converter is nil,
+// ErrInvalidValue if val or the result is NaN or infinite and ErrOutOfDomain if
+// val is not valid for a unit created by FuncConverter. A zero result is
+// always a positive zero.
+func ConvertValue(val float64, from, to Converter) (float64, error) {
+	if from == nil || to == nil {
+		return 0, ErrMissingData
+	}
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return 0, Error(ErrInvalidValue, strconv.FormatFloat(val, 'g', -1, 64))
+	}
+	// units of other types do not know about the validity of a funcConverter
+	// they convert to, so check it here.
+	if ft, ok := to.(funcConverter); ok {
+		if bc, ok := from.(BaseConverter); ok && !ft.accepts(bc.ConvertToBase(val)) {
+			return 0, Error(ErrOutOfDomain, strconv.FormatFloat(val, 'g', -1, 64)+" "+from.Name()+" in "+ft.name)
+		}
+	}
+	v, err := from.Convert(val, to)
+	if errors.Is(err, ErrIncompatibleUnits) {
+		// from may not know the type of to; go through the base UOM instead.
+		if bv, ok := viaBase(val, from, to); ok {
+			v, err = bv, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return checkResult(v, val, from.Name(), to.Name())
+}
+
+// checkResult returns v, the result of converting val from the unit called
+// from to the unit called to, with the sign of a negative zero dropped. It
+// returns ErrInvalidValue if v is NaN or infinite.
+func checkResult(v, val float64, from, to string) (float64, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, Error(ErrInvalidValue, strconv.FormatFloat(val, 'g', -1, 64)+" "+from+" in "+to+" is "+strconv.FormatFloat(v, 'g', -1, 64))
 	}
-	t, ok := store.get(strings.ToLower(to))
+	if v == 0 {
+		return 0, nil
+	}
+	return v, nil
+}
+
+// viaBase converts val from the unit of from to the unit of to through their
+// common base UOM. It reports false if either is not a BaseConverter or they
+// differ in base UOM or category.
+func viaBase(val float64, from, to Converter) (float64, bool) {
+	f, ok := from.(BaseConverter)
 	if !ok {
-		return 0, Error(ErrUnknownUnit, to)
+		return 0, false
+	}
+	t, ok := to.(BaseConverter)
+	if !ok || from.BaseUOM() != to.BaseUOM() || from.Category() != to.Category() {
+		return 0, false
 	}
-	return f.Convert(val, t)
+	return t.ConvertFromBase(f.ConvertToBase(val)), true
+}
+
+// ToValueSameBase converts val from the unit specified by from to the unit
+// specified by to like ToValue, but only requires both units to have the same
+// base UOM; their categories may differ. This allows conversions between units
+// that a dataset files under different categories of the same dimension.
+// Values are checked, and OnConvert called, as for ToValue.
+func ToValueSameBase(val float64, from, to string) (float64, error) {
+	return store.ToValueSameBase(val, from, to)
+}
+
+// Compatible reports whether the units specified by from and to share a
+// category and base UOM and can therefore be converted into each other. It
+// returns an error only if either unit is unknown.
+func Compatible(from, to string) (bool, error) {
+	return store.Compatible(from, to)
+}
+
+// A recategorizer is a Converter that can return a copy of itself in another
+// category.
+type recategorizer interface {
+	inCategory(category string) Converter
+}
+
+// A rebaser is a Converter that can return a copy of itself with another name
+// for its base UOM.
+type rebaser interface {
+	withBase(baseuom string) Converter
+}
+
+// A renamer is a Converter that can return a copy of itself with another name.
+type renamer interface {
+	withName(name string) Converter
+}
+
+// ToValueCase converts val from the unit specified by from to the unit
+// specified by to like ToValue. If caseSensitive is true, the unit names must
+// match the case of the registered names exactly, so that e.g. megameters,
+// "Mm", and millimeters, "mm", can be registered and converted side by side;
+// ErrUnknownUnit is returned otherwise. Units whose names differ only in case
+// share one entry of the store: case-insensitive lookups such as ToValue
+// return the one registered first, and removing either removes both.
+func ToValueCase(val float64, from, to string, caseSensitive bool) (float64, error) {
+	return store.ToValueCase(val, from, to, caseSensitive)
+}
+
+// ConvertResponse describes the result of a conversion, as returned by
+// ConvertRequest.Do and written as JSON by ToJson. Value, the value converted,
+// is always present in the JSON, so that a zero input is reported.
+type ConvertResponse struct {
+	Ok         bool     `json:"ok"`
+	Code       string   `json:"code,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Value      float64  `json:"value"`
+	Result     float64  `json:"result,omitempty"`
+	Rounded    *float64 `json:"roundedresult,omitempty"`
+	Formatted  string   `json:"formatted,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	From       string   `json:"from,omitempty"`
+	FromSymbol string   `json:"fromsymbol,omitempty"`
+	To         string   `json:"to,omitempty"`
+	ToSymbol   string   `json:"tosymbol,omitempty"`
+	BaseUOM    string   `json:"baseuom,omitempty"`
+	Exact      bool     `json:"exact,omitempty"`
 }
 
 // ToJson converts val from the unit specified by from to the unit specified by
 // to and returns the result as a JSON formatted byte slice with information
 // about the conversion.
 func ToJson(val float64, from, to string) ([]byte, error) {
-	type response struct {
-		Ok         bool    `json:"ok"`
-		Message    string  `json:"message,omitempty"`
-		Result     float64 `json:"result,omitempty"`
-		Category   string  `json:"category,omitempty"`
-		From       string  `json:"from,omitempty"`
-		FromSymbol string  `json:"fromsymbol,omitempty"`
-		To         string  `json:"to,omitempty"`
-		ToSymbol   string  `json:"tosymbol,omitempty"`
-		BaseUOM    string  `json:"baseuom,omitempty"`
-	}
-
-	f, _ := store.get(strings.ToLower(from))
-	t, _ := store.get(strings.ToLower(to))
-	val, err := ToValue(val, from, to)
-	if err != nil {
-		msg := err.Error()
-		resp := response{
-			Ok:      false,
-			Message: msg,
+	return store.ToJson(val, from, to)
+}
+
+type ConverterReader interface {
+	ReadFile(string) ([]Converter, error)
+}
+
+// An ExtensionReader is a ConverterReader that reports the file extensions,
+// including the leading dot, of the files it can read. AddFromFiles only
+// passes it files with one of these extensions. ConverterReaders that do not
+// implement ExtensionReader are passed ".json" files.
+type ExtensionReader interface {
+	ConverterReader
+	Extensions() []string
+}
+
+// canRead reports whether reader can read file, based on its extension.
+func canRead(reader ConverterReader, file string) bool {
+	exts := []string{".json"}
+	if er, ok := reader.(ExtensionReader); ok {
+		exts = er.Extensions()
+	}
+	for _, ext := range exts {
+		if strings.HasSuffix(file, ext) {
+			return true
 		}
-		return json.Marshal(resp)
 	}
+	return false
+}
 
-	resp := response{
-		Ok:         true,
-		Message:    "success",
-		Result:     val,
-		Category:   t.Category(),
-		From:       from,
-		FromSymbol: f.Symbol(),
-		To:         to,
-		ToSymbol:   t.Symbol(),
-		BaseUOM:    t.BaseUOM(),
-	}
-	return json.Marshal(resp)
+// AddFromFiles adds/updates the Converters read by reader from the files
+// matching the glob pattern path to/in the store. Files whose content has not
+// changed since they were last loaded into the store are skipped, and units
+// that are unchanged are left alone, so calling it again only updates what
+// changed and only reports those changes to OnChange.
+func AddFromFiles(reader ConverterReader, path string) error {
+	return store.AddFromFiles(reader, path)
 }
 
-// converterStore is a thread-safe in-memory store for Converters.
-type converterStore struct {
-	mu   sync.RWMutex
-	data map[string]Converter
+// AddFromFilesContext is like AddFromFiles but stops before reading the next
+// file and returns ctx.Err() once ctx is done. Converters from files that were
+// read before ctx was done remain in the store.
+func AddFromFilesContext(ctx context.Context, reader ConverterReader, path string) error {
+	return store.AddFromFilesContext(ctx, reader, path)
 }
 
-// store is the global instance of the converterStore.
-var store = converterStore{data: make(map[string]Converter)}
+// AddFromFilesExcluding is like AddFromFiles but skips the files that match
+// any of the glob patterns in exclude, e.g. "*.test.json". A pattern matches a
+// file if it matches either its path or its base name. An error is returned,
+// and nothing is read, if any pattern is malformed.
+func AddFromFilesExcluding(reader ConverterReader, path string, exclude []string) error {
+	return store.AddFromFilesExcluding(reader, path, exclude)
+}
 
-type ConverterReader interface {
-	ReadFile(string) ([]Converter, error)
+// AddFromDir adds/updates the Converters read by reader from the files in root
+// and all its subdirectories to/in the store, visiting them in lexical order.
+// Only files with an extension the reader supports are read, as with
+// AddFromFiles. Subdirectories that cannot be read are skipped.
+func AddFromDir(reader ConverterReader, root string) error {
+	return store.AddFromDir(reader, root)
 }
 
-func AddFromFiles(reader ConverterReader, path string) error {
-	files, err := filepath.Glob(path)
-	if err != nil {
-		return err
-	}
+// ReloadFromFiles replaces all Converters in the store with those read by
+// reader from the files matching the glob pattern path, in one step: if any
+// file cannot be read, the store is left unchanged, and otherwise conversions
+// see either the old or the new units, never a mix. Conversions registered
+// with AddPairwise are dropped.
+func ReloadFromFiles(reader ConverterReader, path string) error {
+	return store.ReloadFromFiles(reader, path)
+}
 
-	for _, file := range files {
-		if strings.HasSuffix(file, ".json") {
-			cs, err := reader.ReadFile(file)
-			if err != nil {
-				return err
-			}
-			for _, c := range cs {
-				store.add(c)
-			}
-		}
-	}
-	return nil
+// Source returns the file the Converter specified by name was read from by
+// AddFromFiles or a related function; for AddFromFS and LoadDefaults, it is
+// the path within the fs.FS. It returns false if the unit is unknown or was
+// not read from a file, e.g. because it was added with AddConverter.
+func Source(name string) (string, bool) {
+	return store.Source(name)
 }
 
-// get retrieves a Converter from store based on the provided name. It
-// returns the Converter and a boolean indicating whether it was found in the
-// store.
-func (s *converterStore) get(name string) (Converter, bool) {
-	store.mu.RLock()
-	defer store.mu.RUnlock()
+// RegisterBaseAlias records aliases as alternative spellings of the base UOM
+// canonical, e.g. "metre" for "meter". Converters read by AddFromFiles
+// afterwards whose base UOM matches an alias, ignoring case, adopt canonical
+// as their base UOM, so units from files that spell the base UOM differently
+// can be converted into each other. Converters already in the store are not
+// changed.
+func RegisterBaseAlias(canonical string, aliases ...string) {
+	store.RegisterBaseAlias(canonical, aliases...)
+}
 
-	if c, ok := store.data[strings.ToLower(name)]; ok {
-		return c, true
-	}
+// AddConverter adds/updates a Converter to/in the store. It returns
+// ErrStoreFull if the store is full; see SetMaxUnits.
+func AddConverter(c Converter) error {
+	return store.Add(c)
+}
+
+// RegisterAll adds/updates all Converters in cs to/in the store at once. If any
+// Converter is nil or has no name, RegisterAll returns an error identifying the
+// first such Converter and the store is left unchanged, as it is if the new
+// units do not fit in a store that rejects units when full; see SetMaxUnits.
+func RegisterAll(cs []Converter) error {
+	return store.RegisterAll(cs)
+}
+
+// AddConverterStrict adds/updates a Converter to/in the store like
+// AddConverter, but returns ErrDuplicateUnit instead of replacing a Converter
+// with the same name in a different category.
+func AddConverterStrict(c Converter) error {
+	return store.AddStrict(c)
+}
+
+// UpdateFactor replaces the factor and offset of the linear unit specified by
+// name with an updated copy, in one step, so that no conversion sees the unit
+// missing. It returns ErrUnknownUnit if the unit is unknown,
+// ErrIncompatibleUnits if it is not a linear unit and ErrZeroNotAllowed if
+// factor is zero. The updated unit is not marked exact; see Exacter.
+func UpdateFactor(name string, factor, offset float64) error {
+	return store.UpdateFactor(name, factor, offset)
+}
 
-	return nil, false
+// Rename renames the unit specified by oldName to newName, keeping its
+// definition, the file it was read from, its position in the order of
+// registration and the conversions registered for it with AddPairwise. It
+// returns ErrUnknownUnit if oldName is unknown, ErrDuplicateUnit if newName
+// names a unit in a different category, which is otherwise replaced, and
+// ErrIncompatibleUnits if the Converter cannot be renamed because it is not
+// one of the package's types.
+func Rename(oldName, newName string) error {
+	return store.Rename(oldName, newName)
 }
 
-// AddConverter adds/updates a Converter to/in the store.
-func (s *converterStore) add(c Converter) {
-	store.mu.Lock()
-	defer store.mu.Unlock()
-	store.data[strings.ToLower(c.Name())] = c
+// RemoveConverter removes the Converter specified by name from the store. If
+// the Converter is not in the store, it does nothing.
+func RemoveConverter(name string) {
+	store.Remove(name)
 }
 
-// RemoveConverter removes a Converter from the cache based on the provided
-// name. If the Converter is not in the cache, it does nothing.
-func (s *converterStore) remove(name string) {
-	store.mu.Lock()
-	defer store.mu.Unlock()
-	delete(store.data, strings.ToLower(name))
+// RemoveCategory removes all Converters whose category matches category,
+// ignoring case, from the store and returns how many were removed.
+func RemoveCategory(category string) int {
+	return store.RemoveCategory(category)
 }
 
-// Clear removes all Converters from the cache.
-func (s *converterStore) clear() {
-	store.mu.Lock()
-	defer store.mu.Unlock()
-	store.data = make(map[string]Converter)
+// Clear removes all Converters from the store.
+func Clear() {
+	store.Clear()
 }
 
-// Categories returns a list of all categories of Converters in the cache.
+// Categories returns a list of all categories of Converters in the store,
+// sorted by name ignoring case. Categories are case-sensitive, so names that
+// differ only in case, such as "Length" and "length", are listed separately,
+// in byte order.
 func Categories() []string {
-	store.mu.RLock()
-	defer store.mu.RUnlock()
+	return store.Categories()
+}
 
-	categories := make(map[string]bool)
-	for _, c := range store.data {
-		categories[c.Category()] = true
+// CategoriesWithCounts returns the number of Converters in each category in
+// the store, keyed by category.
+func CategoriesWithCounts() map[string]int {
+	return store.CategoriesWithCounts()
+}
+
+// CategoryInfo summarizes a category of units in the store.
+type CategoryInfo struct {
+	Category  string   `json:"category"`
+	BaseUOM   string   `json:"baseUOM"`            // base UOM of the units; empty if they differ.
+	UnitCount int      `json:"unitCount"`          // number of units in the category.
+	BaseUOMs  []string `json:"baseUOMs,omitempty"` // the differing base UOMs, sorted; nil if consistent.
+}
+
+// Overview returns a CategoryInfo for every category in the store, sorted like
+// Categories. A category whose units have different base UOMs, which cannot
+// all be converted into each other, has an empty BaseUOM and lists them in
+// BaseUOMs.
+func Overview() []CategoryInfo {
+	return store.Overview()
+}
+
+type Uom struct {
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol"`
+	Category    string `json:"category"`
+	BaseUOM     string `json:"baseUOM"`
+	Description string `json:"description,omitempty"`
+}
+
+// newUom returns the Uom describing c.
+func newUom(c Converter) Uom {
+	u := Uom{
+		Name:     c.Name(),
+		Symbol:   c.Symbol(),
+		Category: c.Category(),
+		BaseUOM:  c.BaseUOM(),
 	}
+	if d, ok := c.(Describer); ok {
+		u.Description = d.Description()
+	}
+	return u
+}
 
-	result := make([]string, 0, len(categories))
-	for c := range categories {
-		result = append(result, c)
+// UnmarshalJSON decodes a Uom from data with the layout it is encoded in. It
+// returns ErrMissingData if the name or the base UOM is empty, so that
+// malformed unit lists are rejected rather than yielding empty units.
+func (u *Uom) UnmarshalJSON(data []byte) error {
+	// uom has the fields and tags of Uom, but not its methods.
+	type uom Uom
+	var v uom
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Name == "" {
+		return Error(ErrMissingData, "uom: name")
 	}
+	if v.BaseUOM == "" {
+		return Error(ErrMissingData, "uom "+v.Name+": baseUOM")
+	}
+	*u = Uom(v)
+	return nil
+}
 
-	slices.SortFunc(result, func(a, b string) int {
-		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
-	})
+// UnitsByCategory returns the units in category sorted by name.
+func UnitsByCategory(category string) []Uom {
+	return store.UnitsByCategory(category)
+}
 
-	return result
+// CompatibleUnits returns the other units with the same base UOM as the unit
+// specified by name, sorted by name. Units are included regardless of their
+// category, which makes the list robust against datasets that file units of
+// one dimension under differently labeled categories. ErrUnknownUnit is
+// returned if the unit is unknown.
+func CompatibleUnits(name string) ([]Uom, error) {
+	return store.CompatibleUnits(name)
 }
 
-type Uom struct {
-	Name     string `json:"name"`
-	Symbol   string `json:"symbol"`
-	Category string `json:"category"`
-	BaseUOM  string `json:"baseUOM"`
+// FilterConverters returns the Converters in the store for which pred returns
+// true, sorted by name, e.g. those of a given type:
+//
+//	rates := convert.FilterConverters(func(c convert.Converter) bool {
+//		_, ok := c.(MyConverter)
+//		return ok
+//	})
+//
+// pred is called while the store is locked for reading, so it must not change
+// the store.
+func FilterConverters(pred func(Converter) bool) []Converter {
+	return store.FilterConverters(pred)
 }
 
-func UnitsByCategory(category string) []Uom {
-	units := make([]Uom, 0, len(store.data))
-	for _, c := range store.data {
-		if c.Category() == category {
-			units = append(units, Uom{
-				Name:     c.Name(),
-				Symbol:   c.Symbol(),
-				Category: c.Category(),
-				BaseUOM:  c.BaseUOM(),
-			})
-		}
-	}
+// A SortKey selects the order of the units returned by UnitsByCategorySorted.
+type SortKey int
+
+const (
+	SortByName      SortKey = iota // case-insensitive name.
+	SortBySymbol                   // symbol, then name.
+	SortByFactor                   // factor to the base UOM, smallest first, then name.
+	SortByInsertion                // order in which the units were first registered.
+)
 
-	slices.SortFunc(units, func(a, b Uom) int {
-		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
-	})
+// UnitsByCategorySorted returns the units in category sorted by the key
+// specified by by. When sorting by factor, units without a factor, i.e. those
+// whose Converter has no Factor method, follow the others in name order.
+func UnitsByCategorySorted(category string, by SortKey) []Uom {
+	return store.UnitsByCategorySorted(category, by)
+}
+
+// UnitsByCategoryOrdered returns the units in category in the order in which
+// they were first registered, e.g. as authored in the files read by
+// AddFromFiles. Updating a unit keeps its position; removing it and adding it
+// again moves it to the end.
+func UnitsByCategoryOrdered(category string) []Uom {
+	return store.UnitsByCategoryOrdered(category)
+}
 
-	return units
+// CategoryDescription returns the description of category as provided by the
+// Converters registered in it, or an empty string if none carries one.
+func CategoryDescription(category string) string {
+	return store.CategoryDescription(category)
+}
 
+// BaseUOM returns the base UOM shared by the units in category, e.g. to label
+// values stored with ToBase. It returns ErrUnknownUnit if the category has no
+// units and ErrInconsistentCategory, listing the base UOMs found, if its units
+// do not all have the same base UOM.
+func BaseUOM(category string) (string, error) {
+	return store.BaseUOM(category)
 }
 
+// Error returns err annotated with msg. The result wraps err, so it can be
+// matched with errors.Is.
 func Error(err error, msg string) error {
 	if err != nil {
-		return errors.New(err.Error() + ": " + msg)
+		return fmt.Errorf("%w: %s", err, msg)
 	}
 	return err
 }
+
+// errorCodes maps the errors returned by the package to the stable codes
+// reported in ToJson responses.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrUnknownUnit, "unknown_unit"},
+	{ErrCategoryMismatch, "category_mismatch"},
+	{ErrMissingData, "missing_data"},
+	{ErrZeroNotAllowed, "zero_not_allowed"},
+	{ErrIncompatibleUnits, "incompatible_units"},
+	{ErrNotExportable, "not_exportable"},
+	{ErrDuplicateUnit, "duplicate_unit"},
+	{ErrInconsistentCategory, "inconsistent_category"},
+	{ErrMalformedData, "malformed_data"},
+	{ErrOutOfDomain, "out_of_domain"},
+	{ErrInvalidValue, "invalid_value"},
+	{ErrStoreFull, "store_full"},
+}
+
+// errorCode returns the stable code of err, or "error" if err is not one of
+// the errors returned by the package.
+func errorCode(err error) string {
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return ec.code
+		}
+	}
+	return "error"
+}
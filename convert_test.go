@@ -0,0 +1,783 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newDefaultStore returns a new Store holding the bundled units.
+func newDefaultStore(t testing.TB) *Store {
+	t.Helper()
+	s := NewStore()
+	if err := s.LoadDefaults(); err != nil {
+		t.Fatalf("LoadDefaults: %v", err)
+	}
+	return s
+}
+
+// approx reports whether a and b agree to within a relative 1e-9.
+func approx(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestMatchPlurals(t *testing.T) {
+	MatchPlurals = true
+	defer func() { MatchPlurals = false }()
+
+	s := newDefaultStore(t)
+	tests := []struct {
+		from string
+		to   string
+		want float64
+	}{
+		{"miles", "meter", 1609.344},
+		{"inches", "meter", 0.0254},
+		{"feet", "meter", 0.3048},
+		{"meters", "meter", 1},
+	}
+	for _, tt := range tests {
+		got, err := s.ToValue(1, tt.from, tt.to)
+		if err != nil || !approx(got, tt.want) {
+			t.Errorf("ToValue(1, %q, %q) = %v, %v; want %v", tt.from, tt.to, got, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultsNameClashes(t *testing.T) {
+	s := newDefaultStore(t)
+	// the clashes documented by LoadDefaults.
+	want := []string{"dyne-centimeter", "liter", "mach", "newton-meter", "quart"}
+	errs := s.Audit()
+	if len(errs) != len(want) {
+		t.Errorf("Audit() = %v; want clashes for %v", errs, want)
+	}
+	for i, err := range errs {
+		if i < len(want) && !strings.Contains(strings.ToLower(err.Error()), want[i]+":") {
+			t.Errorf("Audit()[%d] = %v; want a clash for %s", i, err, want[i])
+		}
+	}
+
+	if c, _ := s.Get("liter"); c.Category() != "Volume (dry)" {
+		t.Errorf("liter is in %s; want Volume (dry)", c.Category())
+	}
+	if c, _ := s.Get("newton-meter"); c.Category() != "Energy" {
+		t.Errorf("newton-meter is in %s; want Energy", c.Category())
+	}
+	if c, _ := s.getCase("Newton-meter", true); c == nil || c.Category() != "Torque" {
+		t.Errorf("Newton-meter is %v; want a unit of Torque", c)
+	}
+	if got, err := s.ToValue(1, "mach", "meters per second"); err != nil || got != 343.2 {
+		t.Errorf("ToValue(1, mach, meters per second) = %v, %v; want 343.2", got, err)
+	}
+}
+
+func TestToValueRejectsNonFiniteResults(t *testing.T) {
+	s := newDefaultStore(t)
+	tests := []struct {
+		val      float64
+		from, to string
+	}{
+		{math.NaN(), "foot", "meter"},
+		{math.Inf(1), "foot", "meter"},
+		{1e308, "meter", "foot"},
+		{-1e308, "meter", "foot"},
+	}
+	for _, tt := range tests {
+		got, err := s.ToValue(tt.val, tt.from, tt.to)
+		if !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("ToValue(%v, %q, %q) = %v, %v; want ErrInvalidValue", tt.val, tt.from, tt.to, got, err)
+		}
+	}
+
+	// the same through ConvertValue, without the store's cache.
+	f, _ := s.Get("meter")
+	to, _ := s.Get("foot")
+	if got, err := ConvertValue(1e308, f, to); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("ConvertValue(1e308, meter, foot) = %v, %v; want ErrInvalidValue", got, err)
+	}
+}
+
+// newFuelStore returns a new Store with a linear and a reciprocal unit of fuel
+// consumption.
+func newFuelStore(t *testing.T) *Store {
+	t.Helper()
+	per100, err := LinearConverter("l100km", "L/100km", "l100km", "Fuel", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mpg, err := ReciprocalConverter("mpg", "mpg", "l100km", "Fuel", 235.215)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStore()
+	s.Add(per100)
+	s.Add(mpg)
+	return s
+}
+
+func TestReciprocalZeroBase(t *testing.T) {
+	s := newFuelStore(t)
+	if got, err := s.ToValue(0, "l100km", "mpg"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("ToValue(0, l100km, mpg) = %v, %v; want ErrInvalidValue", got, err)
+	}
+	if got, err := s.FromBase(0, "mpg"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("FromBase(0, mpg) = %v, %v; want ErrInvalidValue", got, err)
+	}
+
+	b, err := s.ToJson(0, "l100km", "mpg")
+	if err != nil {
+		t.Fatalf("ToJson(0, l100km, mpg): %v", err)
+	}
+	var resp ConvertResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Ok || resp.Code != "invalid_value" {
+		t.Errorf("ToJson(0, l100km, mpg) = %s; want ok false and code invalid_value", b)
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/?value=0&from=l100km&to=mpg", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ServeHTTP status = %d; want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRegisterDataStorageSkipsDuplicates(t *testing.T) {
+	WithConverters(nil, func() {
+		Clear()
+		if err := LoadDefaults(); err != nil {
+			t.Fatal(err)
+		}
+		if err := RegisterDataStorage(); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"KiB", "MiB", "EiB"} {
+			if _, ok := store.Get(name); ok {
+				t.Errorf("%s, a duplicate of a bundled unit, is registered", name)
+			}
+		}
+		tests := []struct {
+			from, to string
+			want     float64
+		}{
+			{"kilobyte", "byte", 1024},
+			{"kB", "byte", 1000},
+			{"megabyte", "MB", 1.048576},
+			{"exabyte", "EB", 1.152921504606846976},
+			{"bit", "byte", 0.125},
+		}
+		for _, tt := range tests {
+			got, err := ToValue(1, tt.from, tt.to)
+			if err != nil || !approx(got, tt.want) {
+				t.Errorf("ToValue(1, %q, %q) = %v, %v; want %v", tt.from, tt.to, got, err, tt.want)
+			}
+		}
+		if c, _ := store.Get("byte"); c.Symbol() != "byte" {
+			t.Errorf("symbol of byte = %q; want the bundled byte", c.Symbol())
+		}
+	})
+
+	s := NewStore()
+	if err := s.registerMissing(DataStorage()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Snapshot()); n != len(DataStorage()) {
+		t.Errorf("registered %d units in an empty store; want %d", n, len(DataStorage()))
+	}
+}
+
+func TestDescriber(t *testing.T) {
+	c, err := MustLinearConverter("meter", "m", "meter", "Length", 1, 0).With(WithDescription("SI unit of length"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := Converter(c).(Describer)
+	if !ok {
+		t.Fatal("linear Converter is not a Describer")
+	}
+	if got := d.Description(); got != "SI unit of length" {
+		t.Errorf("Description() = %q; want SI unit of length", got)
+	}
+
+	s := NewStore()
+	s.Add(c)
+	if us := s.UnitsByCategory("Length"); len(us) != 1 || us[0].Description != "SI unit of length" {
+		t.Errorf("UnitsByCategory(Length) = %+v; want the description", us)
+	}
+}
+
+func TestRates(t *testing.T) {
+	table := NewRateTable()
+	if err := table.Set("usd", 1); err != nil {
+		t.Fatal(err)
+	}
+	dollar, err := BoundRateConverter("dollar", "$", "dollar", "Money", table, "usd", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cent, err := BoundRateConverter("cent", "¢", "dollar", "Money", table, "usd", 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	euro, err := RateConverter("euro", "€", "dollar", "Money", 1.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStore()
+	s.RegisterAll([]Converter{dollar, cent, euro})
+
+	if got, err := s.ToValue(2, "dollar", "cent"); err != nil || !approx(got, 200) {
+		t.Errorf("ToValue(2, dollar, cent) = %v, %v; want 200", got, err)
+	}
+	if got, err := s.ToValue(1, "euro", "dollar"); err != nil || !approx(got, 1.25) {
+		t.Errorf("ToValue(1, euro, dollar) = %v, %v; want 1.25", got, err)
+	}
+	other := NewStore()
+	other.RegisterAll([]Converter{dollar, euro})
+	if err := s.SetRate("euro", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "euro", "cent"); err != nil || !approx(got, 150) {
+		t.Errorf("ToValue(1, euro, cent) after SetRate = %v, %v; want 150", got, err)
+	}
+	if got, _ := other.ToValue(1, "euro", "dollar"); !approx(got, 1.25) {
+		t.Errorf("ToValue(1, euro, dollar) in another store = %v; want 1.25", got)
+	}
+
+	// constructing a unit of the same name does not touch the stores.
+	if _, err := RateConverter("euro", "€", "dollar", "Money", 9); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ToValue(1, "euro", "dollar"); !approx(got, 1.5) {
+		t.Errorf("ToValue(1, euro, dollar) after RateConverter = %v; want 1.5", got)
+	}
+
+	table.Set("usd", 2)
+	if got, err := s.ToValue(1, "euro", "cent"); err != nil || !approx(got, 75) {
+		t.Errorf("ToValue(1, euro, cent) after table.Set = %v, %v; want 75", got, err)
+	}
+	if err := s.SetRate("euro", 0); !errors.Is(err, ErrZeroNotAllowed) {
+		t.Errorf("SetRate(euro, 0) error = %v; want ErrZeroNotAllowed", err)
+	}
+	if err := s.SetRate("cent", 2); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("SetRate(cent) error = %v; want ErrIncompatibleUnits", err)
+	}
+	if err := s.SetRate("peso", 2); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("SetRate(peso) error = %v; want ErrUnknownUnit", err)
+	}
+	if _, err := BoundRateConverter("pound", "£", "dollar", "Money", table, "gbp", 1); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("BoundRateConverter(gbp) error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestRateTable(t *testing.T) {
+	table := NewRateTable()
+	if err := table.Set("USD", 0.02); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Set("eur", 0); !errors.Is(err, ErrZeroNotAllowed) {
+		t.Errorf("Set(eur, 0) error = %v; want ErrZeroNotAllowed", err)
+	}
+	gram := MustLinearConverter("gram of gold", "g", "gram of gold", "Money", 1, 0)
+	dollar, _ := BoundRateConverter("dollar", "$", "gram of gold", "Money", table, "usd", 1)
+	cent, _ := BoundRateConverter("cent", "¢", "gram of gold", "Money", table, "usd", 0.01)
+	s := NewStore()
+	s.RegisterAll([]Converter{gram, dollar, cent})
+
+	for _, tt := range []struct{ rate, dollars, cents float64 }{{0.02, 50, 5000}, {0.025, 40, 4000}} {
+		table.Set("usd", tt.rate)
+		if got, err := s.ToValue(1, "gram of gold", "dollar"); err != nil || !approx(got, tt.dollars) {
+			t.Errorf("ToValue(1, gram of gold, dollar) at %v = %v, %v; want %v", tt.rate, got, err, tt.dollars)
+		}
+		if got, err := s.ToValue(1, "gram of gold", "cent"); err != nil || !approx(got, tt.cents) {
+			t.Errorf("ToValue(1, gram of gold, cent) at %v = %v, %v; want %v", tt.rate, got, err, tt.cents)
+		}
+	}
+}
+
+func TestRatesWithLinearUnits(t *testing.T) {
+	euro, err := RateConverter("euro", "€", "dollar", "Money", 1.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dollar := MustLinearConverter("dollar", "$", "dollar", "Money", 1, 0)
+	cent := MustLinearConverter("cent", "¢", "dollar", "Money", 0.01, 0)
+	s := NewStore()
+	s.RegisterAll([]Converter{euro, dollar, cent})
+
+	tests := []struct {
+		val      float64
+		from, to string
+		want     float64
+	}{
+		{1, "euro", "cent", 125},
+		{250, "cent", "euro", 2},
+		{2, "euro", "dollar", 2.5},
+	}
+	for _, tt := range tests {
+		if got, err := s.ToValue(tt.val, tt.from, tt.to); err != nil || !approx(got, tt.want) {
+			t.Errorf("ToValue(%v, %s, %s) = %v, %v; want %v", tt.val, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+	if got, err := ConvertValue(1, euro, cent); err != nil || !approx(got, 125) {
+		t.Errorf("ConvertValue(1, euro, cent) = %v, %v; want 125", got, err)
+	}
+	if got, base, err := s.ToBase(4, "euro"); err != nil || !approx(got, 5) || base != "dollar" {
+		t.Errorf("ToBase(4, euro) = %v, %q, %v; want 5 dollar", got, base, err)
+	}
+
+	if err := s.SetRate("euro", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(150, "cent", "euro"); err != nil || !approx(got, 1) {
+		t.Errorf("ToValue(150, cent, euro) after SetRate = %v, %v; want 1", got, err)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := newDefaultStore(t)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"value=1&from=inch&to=centimeter", http.StatusOK},
+		{"from=inch&to=centimeter", http.StatusBadRequest},
+		{"value=x&from=inch&to=centimeter", http.StatusBadRequest},
+		{"value=1&from=inch&to=kilogram", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		res, err := http.Get(srv.URL + "?" + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp ConvertResponse
+		err = json.NewDecoder(res.Body).Decode(&resp)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if res.StatusCode != tt.status || resp.Ok != (tt.status == http.StatusOK) {
+			t.Errorf("GET ?%s = %d %+v; want %d", tt.query, res.StatusCode, resp, tt.status)
+		}
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	tests := []struct {
+		val      float64
+		decimals int
+		want     string
+	}{
+		{1.5, 2, "1.5"},
+		{2.345, 2, "2.35"},
+		{1e20, 0, "100000000000000000000"},
+		{0.1, -1, "0.1"},
+		{-0.0001, 2, "0"},
+	}
+	for _, tt := range tests {
+		if got := FormatResult(tt.val, tt.decimals); got != tt.want {
+			t.Errorf("FormatResult(%v, %d) = %q; want %q", tt.val, tt.decimals, got, tt.want)
+		}
+		if tt.decimals >= 0 {
+			if got, want := RoundResult(tt.val, tt.decimals), FormatResult(tt.val, tt.decimals); FormatResult(got, -1) != want {
+				t.Errorf("RoundResult(%v, %d) = %v; disagrees with FormatResult %q", tt.val, tt.decimals, got, want)
+			}
+		}
+	}
+}
+
+func FuzzFormatResult(f *testing.F) {
+	for _, v := range []float64{0, 1.5, -2.345, 1e20, 1e21, 1e-16, 123456.789, math.MaxFloat64} {
+		f.Add(v, int8(2))
+		f.Add(v, int8(-1))
+	}
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+
+	f.Fuzz(func(t *testing.T, val float64, d int8) {
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			t.Skip()
+		}
+		decimals := int(d) % 21
+		got := FormatResult(val, decimals)
+		abs := math.Abs(val)
+		if strings.ContainsAny(got, "eE") && abs < 1e21 && (decimals >= 0 || abs >= 1e-15) {
+			t.Fatalf("FormatResult(%v, %d) = %q; want no exponent", val, decimals, got)
+		}
+		p, err := strconv.ParseFloat(got, 64)
+		if err != nil {
+			t.Fatalf("FormatResult(%v, %d) = %q: %v", val, decimals, got, err)
+		}
+		want := val
+		if decimals >= 0 {
+			want = RoundResult(val, decimals)
+		}
+		if p != want && !(p == 0 && want == 0) {
+			t.Fatalf("FormatResult(%v, %d) = %q parses as %v; want %v", val, decimals, got, p, want)
+		}
+
+		b, err := s.ToJsonWith(val, "meter", "meter", JsonOptions{Format: true, Decimals: decimals})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp ConvertResponse
+		if err := json.Unmarshal(b, &resp); err != nil {
+			t.Fatalf("ToJsonWith(%v) = %s: %v", val, b, err)
+		}
+		if resp.Formatted != got {
+			t.Fatalf("ToJsonWith(%v, %d) formatted %q; want %q", val, decimals, resp.Formatted, got)
+		}
+	})
+}
+
+func TestToJsonReportsErrors(t *testing.T) {
+	s := newDefaultStore(t)
+	b, err := s.ToJson(0, "smoot", "meter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp ConvertResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Ok || resp.Code != "unknown_unit" || !strings.Contains(string(b), `"value":0`) {
+		t.Errorf("ToJson(0, smoot, meter) = %s; want ok false, code unknown_unit and value 0", b)
+	}
+}
+
+func TestToBaseRejectsNonFiniteResults(t *testing.T) {
+	s := newFuelStore(t)
+	s.Add(MustLinearConverter("big", "", "l100km", "Fuel", math.MaxFloat64, 0))
+	if got, _, err := s.ToBase(0, "mpg"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("ToBase(0, mpg) = %v, %v; want ErrInvalidValue", got, err)
+	}
+	if got, _, err := s.ToBase(10, "big"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("ToBase(10, big) = %v, %v; want ErrInvalidValue", got, err)
+	}
+	if got, err := s.FromBase(math.MaxFloat64, "l100km"); err != nil || got != math.MaxFloat64 {
+		t.Errorf("FromBase(MaxFloat64, l100km) = %v, %v; want MaxFloat64", got, err)
+	}
+	if got, err := s.FromBase(1e-310, "mpg"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("FromBase(1e-310, mpg) = %v, %v; want ErrInvalidValue", got, err)
+	}
+	if got, base, err := s.ToBase(23.5215, "mpg"); err != nil || base != "l100km" || !approx(got, 10) {
+		t.Errorf("ToBase(23.5215, mpg) = %v, %s, %v; want 10 l100km", got, base, err)
+	}
+}
+
+func TestConvertGeneric(t *testing.T) {
+	units := []Converter{
+		MustLinearConverter("meter", "m", "meter", "Length", 1, 0),
+		MustLinearConverter("centimeter", "cm", "meter", "Length", 0.01, 0),
+		MustLinearConverter("inch", "in", "meter", "Length", 0.0254, 0),
+	}
+	WithConverters(units, func() {
+		if got, err := Convert(float32(1), "inch", "centimeter"); err != nil || got != float32(2.54) {
+			t.Errorf("Convert(float32(1), inch, centimeter) = %v, %v; want 2.54", got, err)
+		}
+		if got, err := Convert(3, "inch", "centimeter"); err != nil || got != 8 {
+			t.Errorf("Convert(3, inch, centimeter) = %v, %v; want 8", got, err)
+		}
+		if got, err := Convert(-1, "inch", "centimeter"); err != nil || got != -3 {
+			t.Errorf("Convert(-1, inch, centimeter) = %v, %v; want -3", got, err)
+		}
+		if got, err := Convert(uint8(150), "centimeter", "meter"); err != nil || got != 2 {
+			t.Errorf("Convert(uint8(150), centimeter, meter) = %v, %v; want 2", got, err)
+		}
+		if _, err := Convert(1, "inch", "smoot"); !errors.Is(err, ErrUnknownUnit) {
+			t.Errorf("Convert(1, inch, smoot) error = %v; want ErrUnknownUnit", err)
+		}
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	s := newDefaultStore(t)
+	got := s.Suggest("kilomter", 1)
+	if want := []string{"kilometer"}; !slices.Equal(got, want) {
+		t.Errorf("Suggest(kilomter, 1) = %v; want %v", got, want)
+	}
+}
+
+func TestLinearConverterJSON(t *testing.T) {
+	c := MustLinearConverter("Celsius", "°C", "Fahrenheit", "Temperature", 1.8, 32)
+	if c.Factor() != 1.8 || c.Offset() != 32 {
+		t.Errorf("Factor(), Offset() = %v, %v; want 1.8, 32", c.Factor(), c.Offset())
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"Celsius","symbol":"°C","category":"Temperature","baseuom":"Fahrenheit","factor":1.8,"offset":32}`
+	if string(b) != want {
+		t.Errorf("json.Marshal = %s; want %s", b, want)
+	}
+}
+
+func TestRegisterBuiltinsReportsFullStore(t *testing.T) {
+	for name, register := range map[string]func() error{
+		"RegisterRatios":      RegisterRatios,
+		"RegisterAngles":      RegisterAngles,
+		"RegisterDataStorage": RegisterDataStorage,
+	} {
+		WithConverters(nil, func() {
+			Clear()
+			SetMaxUnits(1)
+			defer SetMaxUnits(0)
+			if err := register(); !errors.Is(err, ErrStoreFull) {
+				t.Errorf("%s() error = %v; want ErrStoreFull", name, err)
+			}
+			if n := len(Snapshot()); n != 0 {
+				t.Errorf("%s() registered %d units in a full store; want 0", name, n)
+			}
+		})
+	}
+}
+
+func TestVolumeFactors(t *testing.T) {
+	// liter is shadowed by the dry liter in the full defaults, so read the
+	// volume units on their own.
+	s := NewStore()
+	if err := s.AddFromFS(defaults, LinearReader(), "data/volume.json"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		val      float64
+		from, to string
+		want     float64
+	}{
+		{1, "liter", "cubic meter", 0.001},
+		{1e6, "CC", "cubic meter", 1},
+		{1000, "CC", "liter", 1},
+	}
+	for _, tt := range tests {
+		if got, err := s.ToValue(tt.val, tt.from, tt.to); err != nil || !approx(got, tt.want) {
+			t.Errorf("ToValue(%v, %s, %s) = %v, %v; want %v", tt.val, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+}
+
+func TestReciprocalValues(t *testing.T) {
+	s := newFuelStore(t)
+	if got, err := s.ToValue(10, "l100km", "mpg"); err != nil || !approx(got, 23.5215) {
+		t.Errorf("ToValue(10, l100km, mpg) = %v, %v; want 23.5215", got, err)
+	}
+	if got, err := s.ToValue(23.5215, "mpg", "l100km"); err != nil || !approx(got, 10) {
+		t.Errorf("ToValue(23.5215, mpg, l100km) = %v, %v; want 10", got, err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	s := newDefaultStore(t)
+	if got, err := s.Explain("inch", "meter"); err != nil || got != "(val × 0.0254 + 0) ÷ 1" {
+		t.Errorf("Explain(inch, meter) = %q, %v", got, err)
+	}
+	if _, err := s.Explain("inch", "kilogram"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("Explain(inch, kilogram) error = %v; want ErrIncompatibleUnits", err)
+	}
+}
+
+func TestToJsonIndent(t *testing.T) {
+	s := newDefaultStore(t)
+	b, err := s.ToJsonIndent(1, "inch", "centimeter", "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "\n  \"ok\": true") {
+		t.Errorf("ToJsonIndent = %s; want indented keys", b)
+	}
+	if !json.Valid(b) {
+		t.Errorf("ToJsonIndent = %s; want valid JSON", b)
+	}
+}
+
+func TestCompatibleUnits(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0))
+	s.Add(MustLinearConverter("fathom", "", "meter", "Nautical", 1.8288, 0))
+	s.Add(MustLinearConverter("gram", "g", "gram", "Mass", 1, 0))
+	units, err := s.CompatibleUnits("foot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, u := range units {
+		names = append(names, u.Name)
+	}
+	if want := []string{"fathom", "meter"}; !slices.Equal(names, want) {
+		t.Errorf("CompatibleUnits(foot) = %v; want %v", names, want)
+	}
+	if _, err := s.CompatibleUnits("smoot"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("CompatibleUnits(smoot) error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestRunCLI(t *testing.T) {
+	units := []Converter{
+		MustLinearConverter("meter", "m", "meter", "Length", 1, 0),
+		MustLinearConverter("kilometer", "km", "meter", "Length", 1000, 0),
+	}
+	WithConverters(units, func() {
+		tests := []struct {
+			args   []string
+			code   int
+			stdout string
+		}{
+			{[]string{"2", "kilometer", "meter"}, 0, "2000 m\n"},
+			{[]string{"-decimals", "1", "--", "-1.26", "meter", "meter"}, 0, "-1.3 m\n"},
+			{[]string{"1", "smoot", "meter"}, 1, ""},
+			{[]string{"1", "meter"}, 2, ""},
+			{[]string{"x", "meter", "meter"}, 2, ""},
+		}
+		for _, tt := range tests {
+			var stdout, stderr strings.Builder
+			if code := RunCLI(tt.args, &stdout, &stderr); code != tt.code || stdout.String() != tt.stdout {
+				t.Errorf("RunCLI(%q) = %d, %q; want %d, %q", tt.args, code, stdout.String(), tt.code, tt.stdout)
+			}
+		}
+
+		var stdout, stderr strings.Builder
+		if code := RunCLI([]string{"-json", "1", "kilometer", "meter"}, &stdout, &stderr); code != 0 {
+			t.Fatalf("RunCLI(-json) = %d: %s", code, stderr.String())
+		}
+		var resp ConvertResponse
+		if err := json.Unmarshal([]byte(stdout.String()), &resp); err != nil || resp.Result != 1000 || resp.Formatted == "" {
+			t.Errorf("RunCLI(-json) wrote %s; want result 1000 and a formatted result", stdout.String())
+		}
+	})
+}
+
+func TestToJsonStyled(t *testing.T) {
+	s := newDefaultStore(t)
+	tests := []struct {
+		style KeyStyle
+		key   string
+	}{
+		{KeyLower, `"fromsymbol"`},
+		{KeySnake, `"from_symbol"`},
+		{KeyCamel, `"fromSymbol"`},
+	}
+	for _, tt := range tests {
+		b, err := s.ToJsonStyled(1, "inch", "centimeter", tt.style)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), tt.key) {
+			t.Errorf("ToJsonStyled(%v) = %s; want key %s", tt.style, b, tt.key)
+		}
+	}
+}
+
+func TestToJsonRounded(t *testing.T) {
+	s := newDefaultStore(t)
+	b, err := s.ToJsonRounded(1, "inch", "centimeter", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Result  float64  `json:"result"`
+		Rounded *float64 `json:"roundedresult"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Result != 2.54 || resp.Rounded == nil || *resp.Rounded != 2.5 {
+		t.Errorf("ToJsonRounded(1, inch, centimeter, 1) = %s; want result 2.54 and roundedresult 2.5", b)
+	}
+
+	b, err = s.ToJsonRounded(1, "inch", "nosuchunit", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "roundedresult") {
+		t.Errorf("ToJsonRounded for an unknown unit = %s; want no roundedresult", b)
+	}
+}
+
+func TestAngles(t *testing.T) {
+	s := NewStore()
+	s.RegisterAll(Angles())
+	if got, err := s.ToValue(1, "turn", "degree"); err != nil || got != 360 {
+		t.Errorf("ToValue(1, turn, degree) = %v, %v; want 360", got, err)
+	}
+	if got, err := s.ToValueNormalized(450, "degree", "degree"); err != nil || !approx(got, 90) {
+		t.Errorf("ToValueNormalized(450, degree, degree) = %v, %v; want 90", got, err)
+	}
+	if got, err := s.ToValueNormalized(-90, "degree", "degree"); err != nil || !approx(got, 270) {
+		t.Errorf("ToValueNormalized(-90, degree, degree) = %v, %v; want 270", got, err)
+	}
+	if got, err := s.ToValueNormalized(3*math.Pi, "radian", "radian"); err != nil || !approx(got, math.Pi) {
+		t.Errorf("ToValueNormalized(3π, radian, radian) = %v, %v; want π", got, err)
+	}
+}
+
+func TestFormatSymbol(t *testing.T) {
+	tests := []struct{ plain, sup string }{
+		{"m2", "m²"},
+		{"m3", "m³"},
+		{"kg", "kg"},
+	}
+	for _, tt := range tests {
+		if got := FormatSymbol(tt.plain); got != tt.sup {
+			t.Errorf("FormatSymbol(%q) = %q; want %q", tt.plain, got, tt.sup)
+		}
+		if got := FormatSymbolASCII(tt.sup); got != tt.plain {
+			t.Errorf("FormatSymbolASCII(%q) = %q; want %q", tt.sup, got, tt.plain)
+		}
+	}
+	if got := FormatSymbol("s^-1"); got != "s⁻¹" {
+		t.Errorf("FormatSymbol(s^-1) = %q; want s⁻¹", got)
+	}
+}
+
+func TestConvertString(t *testing.T) {
+	s := newDefaultStore(t)
+	if got, err := s.ConvertString(10, "kilometer", "mile", 2); err != nil || got != "6.21 mi" {
+		t.Errorf("ConvertString(10, kilometer, mile, 2) = %q, %v; want 6.21 mi", got, err)
+	}
+}
+
+func TestOverview(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0))
+	s.Add(MustLinearConverter("gram", "g", "gram", "Mass", 1, 0))
+	s.Add(MustLinearConverter("pound", "lb", "kilogram", "Mass", 0.45359237, 0))
+	want := []CategoryInfo{
+		{Category: "Length", BaseUOM: "meter", UnitCount: 2},
+		{Category: "Mass", UnitCount: 2, BaseUOMs: []string{"gram", "kilogram"}},
+	}
+	got := s.Overview()
+	if len(got) != len(want) {
+		t.Fatalf("Overview() = %+v; want %+v", got, want)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Category != w.Category || g.BaseUOM != w.BaseUOM || g.UnitCount != w.UnitCount || !slices.Equal(g.BaseUOMs, w.BaseUOMs) {
+			t.Errorf("Overview()[%d] = %+v; want %+v", i, g, w)
+		}
+	}
+}
+
+func TestConvertRequestDo(t *testing.T) {
+	s := newDefaultStore(t)
+	resp, err := s.Do(ConvertRequest{Value: 1, From: "foot", To: "inch"})
+	if err != nil || !resp.Ok || !approx(resp.Result, 12) || resp.ToSymbol != "in" {
+		t.Errorf("Do(1 foot to inch) = %+v, %v; want 12 in", resp, err)
+	}
+	resp, err = s.Do(ConvertRequest{Value: 1, From: "foot", To: "kilogram"})
+	if !errors.Is(err, ErrIncompatibleUnits) || resp.Ok || resp.Code == "" {
+		t.Errorf("Do(1 foot to kilogram) = %+v, %v; want ErrIncompatibleUnits", resp, err)
+	}
+}
@@ -0,0 +1,100 @@
+package convert
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// csvLayout reads Converter data for linear UOMs from csv files with one unit
+// per row in the form name,symbol,factor[,offset]. The category and base unit
+// are the same for every row and are given when the reader is created.
+type csvLayout struct {
+	category string
+	baseunit string
+}
+
+// CSVReader returns a new instance of csvLayout that can be used to read
+// linear uom data in category with base unit baseunit from a csv file.
+func CSVReader(category, baseunit string) *csvLayout {
+	return &csvLayout{category: category, baseunit: baseunit}
+}
+
+// ReadFile reads the linear UOMs in filename and returns them as Converters. A
+// first row whose factor is not a number is treated as a header and skipped.
+// A missing offset defaults to 0. A malformed row is reported with its line
+// number.
+func (cl *csvLayout) ReadFile(filename string) ([]Converter, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return cl.decode(f, filename)
+}
+
+// Decode reads the linear UOMs from r and returns them as Converters. See
+// ReadFile for details.
+func (cl *csvLayout) Decode(r io.Reader) ([]Converter, error) {
+	return cl.decode(r, "")
+}
+
+// decode reads the linear UOMs from in, naming the file in errors if name is
+// not empty.
+func (cl *csvLayout) decode(in io.Reader, name string) ([]Converter, error) {
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var converters []Converter
+	for first := true; ; first = false {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		rowErr := func(msg string) error {
+			where := "line " + strconv.Itoa(line)
+			if name != "" {
+				where = name + " " + where
+			}
+			return Error(ErrMalformedData, where+": "+msg)
+		}
+
+		if len(record) < 3 || len(record) > 4 {
+			return nil, rowErr("want 3 or 4 fields, got " + strconv.Itoa(len(record)))
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			if first {
+				continue // header row
+			}
+			return nil, rowErr("invalid factor " + record[2])
+		}
+		var offset float64
+		if len(record) == 4 && strings.TrimSpace(record[3]) != "" {
+			offset, err = strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+			if err != nil {
+				return nil, rowErr("invalid offset " + record[3])
+			}
+		}
+
+		newUnit, err := LinearConverter(record[0], record[1], cl.baseunit, cl.category, factor, offset)
+		if err != nil {
+			return nil, rowErr(err.Error())
+		}
+		converters = append(converters, newUnit)
+	}
+	return converters, nil
+}
+
+// Extensions returns the file extensions read by a csvLayout.
+func (cl *csvLayout) Extensions() []string {
+	return []string{".csv"}
+}
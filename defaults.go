@@ -0,0 +1,81 @@
+package convert
+
+import (
+	"embed"
+	"io"
+	"io/fs"
+)
+
+// defaults holds the standard unit definitions bundled with the package.
+//
+//go:embed data/*.json
+var defaults embed.FS
+
+// LoadDefaults adds/updates the standard units bundled with the package to/in
+// the store. It can be called again, e.g. after Clear, to restore them.
+//
+// A few names are defined twice in the bundled data, and the definition read
+// last is kept: "liter" and "quart" are the dry units of "Volume (dry)" rather
+// than those of "Volume", "dyne-centimeter" is a unit of "Torque" rather than
+// "Energy", and "mach" is the speed of sound at 20 °C rather than 0 °C. The
+// "newton-meter" of "Energy" and the "Newton-meter" of "Torque" differ in case
+// and are both kept; see ToValueCase. AuditStore reports these clashes.
+func LoadDefaults() error {
+	return store.LoadDefaults()
+}
+
+// LoadDefaults adds/updates the standard units bundled with the package to/in
+// the store.
+func (s *Store) LoadDefaults() error {
+	return s.AddFromFS(defaults, LinearReader(), "data/*.json")
+}
+
+// A ConverterDecoder is a ConverterReader that can also read Converters from
+// an io.Reader, so that AddFromFS can read files that are not on the operating
+// system's file system. The readers returned by LinearReader, TOMLReader and
+// CSVReader are ConverterDecoders.
+type ConverterDecoder interface {
+	ConverterReader
+	Decode(r io.Reader) ([]Converter, error)
+}
+
+// AddFromFS adds/updates the Converters read by reader from the files in fsys
+// matching the pattern, as used by fs.Glob, to/in the store, e.g. from an
+// embed.FS. Only files with an extension the reader supports are read, as with
+// AddFromFiles. The reader must be a ConverterDecoder; ErrMissingData is
+// returned otherwise. If any file cannot be read, nothing is added.
+func AddFromFS(fsys fs.FS, reader ConverterReader, pattern string) error {
+	return store.AddFromFS(fsys, reader, pattern)
+}
+
+// AddFromFS adds/updates the Converters read by reader from the files in fsys
+// matching pattern to/in the store. See the package-level AddFromFS for
+// details.
+func (s *Store) AddFromFS(fsys fs.FS, reader ConverterReader, pattern string) error {
+	dec, ok := reader.(ConverterDecoder)
+	if !ok {
+		return Error(ErrMissingData, "reader has no Decode method")
+	}
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+
+	var batch []loadedFile
+	for _, file := range files {
+		if !canRead(reader, file) {
+			continue
+		}
+		f, err := fsys.Open(file)
+		if err != nil {
+			return err
+		}
+		cs, err := dec.Decode(f)
+		f.Close()
+		if err != nil {
+			return Error(err, file)
+		}
+		batch = append(batch, loadedFile{file: file, cs: cs})
+	}
+	return s.addLoaded(batch)
+}
@@ -0,0 +1,48 @@
+package convert
+
+// ToValueDelta converts val, a difference between two values in the unit
+// specified by from, to the same difference in the unit specified by to. Unlike
+// ToValue, it does not apply the offsets of the units: a difference of 10
+// degrees Celsius is a difference of 18 degrees Fahrenheit, whereas a
+// temperature of 10 °C is 50 °F. Use ToValue for absolute values, such as
+// readings, and ToValueDelta for differences between them, such as changes
+// and tolerances.
+//
+// A difference only converts independently of the values it lies between if
+// the conversion is affine, i.e. a factor and an offset. Delta conversion is
+// therefore not supported for reciprocal units, for which ErrIncompatibleUnits
+// is returned; units of other types are assumed to be affine.
+func ToValueDelta(val float64, from, to string) (float64, error) {
+	return store.ToValueDelta(val, from, to)
+}
+
+// ToValueDelta converts val, a difference between two values in the unit
+// specified by from, to the unit specified by to. See the package-level
+// ToValueDelta for details.
+func (s *Store) ToValueDelta(val float64, from, to string) (float64, error) {
+	v, err := s.ToValue(val, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	type factorer interface{ Factor() float64 }
+	f, _ := s.Get(from)
+	t, _ := s.Get(to)
+	_, frec := f.(reciprocalConverter)
+	_, trec := t.(reciprocalConverter)
+	if frec || trec {
+		return 0, Error(ErrIncompatibleUnits, "no delta conversion between "+from+" and "+to+": reciprocal units are not affine")
+	}
+	ff, fok := f.(factorer)
+	tf, tok := t.(factorer)
+	if fok && tok && f.BaseUOM() == t.BaseUOM() {
+		return val * ff.Factor() / tf.Factor(), nil
+	}
+
+	// for other units, remove whatever the conversion adds to zero.
+	zero, err := s.toValue(0, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return v - zero, nil
+}
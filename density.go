@@ -0,0 +1,50 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The base UOMs that identify mass and volume units for ConvertWithDensity.
+const (
+	massBase   = "kilogram"
+	volumeBase = "cubic meter"
+)
+
+// ConvertWithDensity converts val between a mass unit and a volume unit, in
+// either direction, using densityKgPerM3, the density of the substance in
+// kilograms per cubic meter; e.g. 200 grams of water, with a density of 1000,
+// are 0.0002 cubic meters. Mass units are those with the base UOM "kilogram"
+// and volume units those with the base UOM "cubic meter", as in the bundled
+// units. ErrIncompatibleUnits is returned unless one unit is a mass unit and
+// the other a volume unit, and ErrMalformedData if densityKgPerM3 is not
+// positive.
+func ConvertWithDensity(val float64, from, to string, densityKgPerM3 float64) (float64, error) {
+	return store.ConvertWithDensity(val, from, to, densityKgPerM3)
+}
+
+// ConvertWithDensity converts val between a mass unit and a volume unit using
+// densityKgPerM3. See the package-level ConvertWithDensity for details.
+func (s *Store) ConvertWithDensity(val float64, from, to string, densityKgPerM3 float64) (float64, error) {
+	if !(densityKgPerM3 > 0) {
+		return 0, Error(ErrMalformedData, "density must be positive: "+strconv.FormatFloat(densityKgPerM3, 'g', -1, 64))
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, to)
+	}
+	b, base, err := s.ToBase(val, from)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case strings.EqualFold(base, massBase) && strings.EqualFold(t.BaseUOM(), volumeBase):
+		b /= densityKgPerM3
+	case strings.EqualFold(base, volumeBase) && strings.EqualFold(t.BaseUOM(), massBase):
+		b *= densityKgPerM3
+	default:
+		return 0, Error(ErrIncompatibleUnits, from+" and "+to+" are not a mass and a volume")
+	}
+	return s.FromBase(b, to)
+}
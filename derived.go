@@ -0,0 +1,118 @@
+package convert
+
+// A DerivedSpec describes a unit derived from another unit by a multiplicative
+// factor: one unit of Name equals Factor units of the unit it is derived from.
+type DerivedSpec struct {
+	Name   string
+	Symbol string
+	Factor float64
+}
+
+// RegisterDerived adds/updates a linear unit to/in the store for each spec in
+// derived, computing its factor and offset from the linear unit specified by
+// baseName, so that e.g. a foot can be derived from an inch with a factor of
+// 12. The derived units share the category and base UOM of baseName. Nothing
+// is registered if baseName is unknown or not linear, or if any spec is
+// invalid.
+func RegisterDerived(baseName string, derived []DerivedSpec) error {
+	return store.RegisterDerived(baseName, derived)
+}
+
+// RegisterDerived adds/updates a linear unit to/in the store for each spec in
+// derived. See the package-level RegisterDerived for details.
+func (s *Store) RegisterDerived(baseName string, derived []DerivedSpec) error {
+	c, ok := s.Get(baseName)
+	if !ok {
+		return Error(ErrUnknownUnit, baseName)
+	}
+	base, ok := c.(linearConverter)
+	if !ok {
+		return Error(ErrIncompatibleUnits, baseName+" is not linear")
+	}
+
+	cs := make([]Converter, 0, len(derived))
+	for _, d := range derived {
+		newUnit, err := base.relative(d.Name, d.Symbol, d.Factor, 0)
+		if err != nil {
+			return Error(err, d.Name)
+		}
+		cs = append(cs, newUnit)
+	}
+	return s.RegisterAll(cs)
+}
+
+// LinearConverterRelative returns a linear unit defined relative to the linear
+// unit specified by relativeTo, which must be registered in the store: a value
+// in the new unit converts to relativeTo as value*factor + offset. The factor
+// and offset against the base UOM are computed by composing both transforms,
+// and the new unit shares the category and base UOM of relativeTo. The unit is
+// not added to the store. It returns ErrUnknownUnit if relativeTo is unknown
+// and ErrIncompatibleUnits if it is not linear.
+func LinearConverterRelative(name, symbol, relativeTo string, factor, offset float64) (linearConverter, error) {
+	return store.LinearConverterRelative(name, symbol, relativeTo, factor, offset)
+}
+
+// LinearConverterRelative returns a linear unit defined relative to the linear
+// unit specified by relativeTo. See the package-level LinearConverterRelative
+// for details.
+func (s *Store) LinearConverterRelative(name, symbol, relativeTo string, factor, offset float64) (linearConverter, error) {
+	c, ok := s.Get(relativeTo)
+	if !ok {
+		return linearConverter{}, Error(ErrUnknownUnit, relativeTo)
+	}
+	rel, ok := c.(linearConverter)
+	if !ok {
+		return linearConverter{}, Error(ErrIncompatibleUnits, relativeTo+" is not linear")
+	}
+
+	return rel.relative(name, symbol, factor, offset)
+}
+
+// relative returns a linear unit that converts to u as value*factor + offset.
+func (u linearConverter) relative(name, symbol string, factor, offset float64) (linearConverter, error) {
+	// base = (value*factor + offset)*u.factor + u.offset
+	return LinearConverter(name, symbol, u.baseuom, u.category, factor*u.factor, offset*u.factor+u.offset)
+}
+
+// DeriveAreaVolume adds/updates the units "<name> squared" and "<name> cubed"
+// to/in the store, derived from the linear length unit specified by
+// lengthUnitName with its factor squared and cubed. They are registered in the
+// categories "Area" and "Volume" of the bundled units, with the base UOMs
+// "<base>²" and "cubic <base>", so that e.g. units derived from meter convert
+// to and from the bundled area and volume units. The symbols of the derived
+// units are the symbol of the length unit followed by "²" and "³". The length
+// unit must not have an offset.
+func DeriveAreaVolume(lengthUnitName string) error {
+	return store.DeriveAreaVolume(lengthUnitName)
+}
+
+// DeriveAreaVolume adds/updates the area and volume units derived from the
+// linear length unit specified by lengthUnitName to/in the store. See the
+// package-level DeriveAreaVolume for details.
+func (s *Store) DeriveAreaVolume(lengthUnitName string) error {
+	c, ok := s.Get(lengthUnitName)
+	if !ok {
+		return Error(ErrUnknownUnit, lengthUnitName)
+	}
+	l, ok := c.(linearConverter)
+	if !ok {
+		return Error(ErrIncompatibleUnits, lengthUnitName+" is not linear")
+	}
+	if l.offset != 0 {
+		return Error(ErrIncompatibleUnits, lengthUnitName+" has an offset")
+	}
+
+	var areaSymbol, volumeSymbol string
+	if l.symbol != "" {
+		areaSymbol, volumeSymbol = l.symbol+"²", l.symbol+"³"
+	}
+	area, err := LinearConverter(l.name+" squared", areaSymbol, l.baseuom+"²", "Area", l.factor*l.factor, 0)
+	if err != nil {
+		return err
+	}
+	volume, err := LinearConverter(l.name+" cubed", volumeSymbol, "cubic "+l.baseuom, "Volume", l.factor*l.factor*l.factor, 0)
+	if err != nil {
+		return err
+	}
+	return s.RegisterAll([]Converter{area, volume})
+}
@@ -0,0 +1,60 @@
+package convert
+
+import (
+	"math"
+	"strconv"
+)
+
+// A Domain is the range of values, in the base UOM of a category, that a unit
+// can physically take, e.g. temperatures at or above absolute zero. Both
+// bounds are inclusive.
+type Domain struct {
+	Min float64
+	Max float64
+}
+
+// Unbounded is the Domain of units without physical limits.
+var Unbounded = Domain{Min: math.Inf(-1), Max: math.Inf(1)}
+
+// Contains reports whether baseVal lies within d.
+func (d Domain) Contains(baseVal float64) bool {
+	return baseVal >= d.Min && baseVal <= d.Max
+}
+
+// A Domainer is a Converter whose unit is restricted to a Domain.
+type Domainer interface {
+	Domain() Domain
+}
+
+// ToValueChecked converts val from the unit specified by from to the unit
+// specified by to like ToValue, but returns ErrOutOfDomain if the result,
+// converted to the base UOM of to, lies outside the Domain of the unit
+// specified by to. Checking the result rather than val also covers units
+// converted through AddPairwise, whose base UOMs differ. Units that are not
+// Domainers are unbounded.
+func ToValueChecked(val float64, from, to string) (float64, error) {
+	return store.ToValueChecked(val, from, to)
+}
+
+// ToValueChecked converts val from the unit specified by from to the unit
+// specified by to. See the package-level ToValueChecked for details.
+func (s *Store) ToValueChecked(val float64, from, to string) (float64, error) {
+	result, err := s.ToValue(val, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	t, _ := s.Get(to)
+	d, ok := t.(Domainer)
+	if !ok {
+		return result, nil
+	}
+	b, _, err := s.ToBase(result, to)
+	if err != nil {
+		return 0, err
+	}
+	if !d.Domain().Contains(b) {
+		return 0, Error(ErrOutOfDomain, strconv.FormatFloat(val, 'g', -1, 64)+" "+from+" in "+to)
+	}
+	return result, nil
+}
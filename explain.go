@@ -0,0 +1,69 @@
+package convert
+
+import "strconv"
+
+// An explainer is a Converter that can describe its conversion to and from
+// the base UOM of its category as a formula.
+type explainer interface {
+	// explainToBase returns the parenthesized formula converting x to the base UOM.
+	explainToBase(x string) string
+	// explainFromBase returns the formula converting x from the base UOM.
+	explainFromBase(x string) string
+}
+
+// Explain returns a human-readable formula for converting a value, val, from
+// the unit specified by from to the unit specified by to, e.g.
+// "(val × 0.0254 + 0) ÷ 1" for inches to meters. Units whose conversion
+// cannot be written as a formula get a description in words instead. It
+// returns the same errors as ToValue for unknown or incompatible units.
+func Explain(from, to string) (string, error) {
+	return store.Explain(from, to)
+}
+
+// Explain returns a human-readable formula for converting a value from the
+// unit specified by from to the unit specified by to. See the package-level
+// Explain for details.
+func (s *Store) Explain(from, to string) (string, error) {
+	f, ok := s.Get(from)
+	if !ok {
+		return "", Error(ErrUnknownUnit, from)
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return "", Error(ErrUnknownUnit, to)
+	}
+	if _, err := ConvertValue(1, f, t); err != nil {
+		return "", err
+	}
+
+	fe, fok := f.(explainer)
+	te, tok := t.(explainer)
+	if fok && tok {
+		return te.explainFromBase(fe.explainToBase("val")), nil
+	}
+	return "val converted from " + f.Name() + " to " + f.BaseUOM() + " and from " + f.BaseUOM() + " to " + t.Name(), nil
+}
+
+// formatNumber formats v for use in a formula.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func (u linearConverter) explainToBase(x string) string {
+	return "(" + x + " × " + formatNumber(u.factor) + " + " + formatNumber(u.offset) + ")"
+}
+
+func (u linearConverter) explainFromBase(x string) string {
+	if u.offset != 0 {
+		x = "(" + x + " − " + formatNumber(u.offset) + ")"
+	}
+	return x + " ÷ " + formatNumber(u.factor)
+}
+
+func (u reciprocalConverter) explainToBase(x string) string {
+	return "(" + formatNumber(u.factor) + " ÷ " + x + ")"
+}
+
+func (u reciprocalConverter) explainFromBase(x string) string {
+	return formatNumber(u.factor) + " ÷ " + x
+}
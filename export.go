@@ -0,0 +1,203 @@
+package convert
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A JSONConverter is a Converter that can serialize itself, so that ExportJSON
+// can write it and ImportJSON can read it back. ConverterType returns the tag
+// under which the constructor of its type is registered with
+// RegisterConverterType.
+type JSONConverter interface {
+	Converter
+	ConverterType() string
+	MarshalConverter() ([]byte, error)
+}
+
+// converterTypes holds the constructors registered with RegisterConverterType,
+// keyed by type tag.
+var converterTypes = struct {
+	mu sync.RWMutex
+	m  map[string]func([]byte) (Converter, error)
+}{m: make(map[string]func([]byte) (Converter, error))}
+
+// RegisterConverterType registers fn as the constructor used by ImportJSON for
+// Converters tagged with typ, i.e. those whose ConverterType method returns
+// typ. fn receives the data returned by MarshalConverter. Registering a type
+// again replaces its constructor.
+func RegisterConverterType(typ string, fn func([]byte) (Converter, error)) {
+	converterTypes.mu.Lock()
+	defer converterTypes.mu.Unlock()
+	converterTypes.m[typ] = fn
+}
+
+// taggedLayout represents a JSONConverter in the format written by ExportJSON.
+type taggedLayout struct {
+	Type      string          `json:"type"`
+	Converter json.RawMessage `json:"converter"`
+}
+
+// ExportJSON writes all linear Converters in the store to w in the format read
+// by LinearReader, one fileLayout object per category and base UOM, followed
+// by one object per JSONConverter holding its type tag and data. The result can
+// be read back with ImportJSON. Other Converters cannot be serialized; they are
+// skipped and reported in an ErrNotExportable error once all others have been
+// written.
+func ExportJSON(w io.Writer) error {
+	return store.ExportJSON(w)
+}
+
+// ExportJSON writes all linear Converters and JSONConverters in the store to
+// w. See the package-level ExportJSON for details.
+func (s *Store) ExportJSON(w io.Writer) error {
+	layouts, tagged, skipped := s.layouts()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	for _, fl := range layouts {
+		if err := enc.Encode(fl); err != nil {
+			return err
+		}
+	}
+	for _, c := range tagged {
+		data, err := c.MarshalConverter()
+		if err != nil {
+			return Error(err, c.Name())
+		}
+		if err := enc.Encode(taggedLayout{Type: c.ConverterType(), Converter: data}); err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		return Error(ErrNotExportable, strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// layouts groups the linear Converters in the store by category and base UOM
+// and returns them as fileLayouts sorted by category, together with the
+// JSONConverters sorted by name and the sorted names of the other Converters.
+func (s *Store) layouts() ([]*fileLayout, []JSONConverter, []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type group struct{ category, baseuom string }
+	groups := make(map[group]*fileLayout)
+	var tagged []JSONConverter
+	var skipped []string
+	for _, c := range s.data {
+		lc, ok := c.(linearConverter)
+		if !ok {
+			if jc, ok := c.(JSONConverter); ok {
+				tagged = append(tagged, jc)
+			} else {
+				skipped = append(skipped, c.Name())
+			}
+			continue
+		}
+
+		g := group{lc.category, lc.baseuom}
+		fl, ok := groups[g]
+		if !ok {
+			fl = &fileLayout{Category: lc.category, BaseUnit: lc.baseuom}
+			groups[g] = fl
+		}
+		if fl.Description == "" {
+			fl.Description = lc.catdesc
+		}
+		if lc.domain != nil && fl.Min == nil && fl.Max == nil {
+			if !math.IsInf(lc.domain.Min, 0) {
+				fl.Min = &lc.domain.Min
+			}
+			if !math.IsInf(lc.domain.Max, 0) {
+				fl.Max = &lc.domain.Max
+			}
+		}
+		fl.Units = append(fl.Units, unitLayout{
+			Name:        lc.name,
+			Symbol:      lc.symbol,
+			Description: lc.description,
+			Factor:      lc.factor,
+			Offset:      lc.offset,
+			Exact:       lc.exact,
+		})
+	}
+
+	layouts := make([]*fileLayout, 0, len(groups))
+	for _, fl := range groups {
+		slices.SortFunc(fl.Units, func(a, b unitLayout) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+		layouts = append(layouts, fl)
+	}
+	slices.SortFunc(layouts, func(a, b *fileLayout) int {
+		return cmp.Or(strings.Compare(a.Category, b.Category), strings.Compare(a.BaseUnit, b.BaseUnit))
+	})
+	slices.SortFunc(tagged, func(a, b JSONConverter) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	slices.Sort(skipped)
+
+	return layouts, tagged, skipped
+}
+
+// ImportJSON adds/updates the Converters written by ExportJSON to/in the
+// store. Objects with a type tag are decoded by the constructor registered for
+// the tag with RegisterConverterType; ErrMalformedData is returned for unknown
+// tags. Untagged objects are read as linear units like LinearReader does. If
+// any object cannot be decoded, the store is left unchanged.
+func ImportJSON(r io.Reader) error {
+	return store.ImportJSON(r)
+}
+
+// ImportJSON adds/updates the Converters written by ExportJSON to/in the
+// store. See the package-level ImportJSON for details.
+func (s *Store) ImportJSON(r io.Reader) error {
+	var cs []Converter
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		var tl taggedLayout
+		if err := json.Unmarshal(raw, &tl); err != nil {
+			return err
+		}
+		if tl.Type == "" {
+			read, err := LinearReader().Decode(bytes.NewReader(raw))
+			if err != nil {
+				return err
+			}
+			cs = append(cs, read...)
+			continue
+		}
+
+		converterTypes.mu.RLock()
+		fn, ok := converterTypes.m[tl.Type]
+		converterTypes.mu.RUnlock()
+		if !ok {
+			return Error(ErrMalformedData, "unknown converter type "+tl.Type)
+		}
+		c, err := fn(tl.Converter)
+		if err != nil {
+			return Error(err, tl.Type)
+		}
+		cs = append(cs, c)
+	}
+	return s.RegisterAll(cs)
+}
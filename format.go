@@ -0,0 +1,229 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FormatResult formats val as a fixed-point decimal string without an
+// exponent, rounded to decimals digits after the decimal point and with
+// trailing zeros trimmed. A negative decimals uses as many digits as needed to
+// represent val exactly. NaN, infinities, magnitudes of 1e21 and above and,
+// when decimals is negative, non-zero magnitudes below 1e-15 are formatted in
+// the shortest representation instead, which may use an exponent.
+func FormatResult(val float64, decimals int) string {
+	abs := math.Abs(val)
+	if math.IsNaN(val) || math.IsInf(val, 0) || abs >= 1e21 || (decimals < 0 && abs != 0 && abs < 1e-15) {
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	}
+
+	s := strconv.FormatFloat(val, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// RoundResult rounds val to decimals digits after the decimal point the same
+// way FormatResult does, so that the two agree. A negative decimals returns val
+// unchanged, as do NaN and infinities.
+func RoundResult(val float64, decimals int) float64 {
+	if decimals < 0 || math.IsNaN(val) || math.IsInf(val, 0) {
+		return val
+	}
+	r, err := strconv.ParseFloat(strconv.FormatFloat(val, 'f', decimals, 64), 64)
+	if err != nil || r == 0 {
+		return 0
+	}
+	return r
+}
+
+// superscripts maps the characters of an exponent to their Unicode
+// superscripts.
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴',
+	'5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹', '-': '⁻',
+}
+
+// FormatSymbol returns sym with a trailing exponent written in Unicode
+// superscripts, so that "m2" becomes "m²" and "s^-1" becomes "s⁻¹". The
+// exponent is a run of digits, optionally preceded by a minus sign and a
+// caret, that follows the rest of the symbol. Symbols without such an exponent
+// are returned unchanged.
+func FormatSymbol(sym string) string {
+	head := strings.TrimRight(sym, "0123456789")
+	if head == sym || head == "" {
+		return sym
+	}
+	exp := sym[len(head):]
+	if strings.HasSuffix(head, "-") {
+		head, exp = head[:len(head)-1], "-"+exp
+	}
+	head = strings.TrimSuffix(head, "^")
+	if head == "" {
+		return sym
+	}
+
+	var b strings.Builder
+	b.WriteString(head)
+	for _, r := range exp {
+		b.WriteRune(superscripts[r])
+	}
+	return b.String()
+}
+
+// FormatSymbolASCII returns sym with Unicode superscripts replaced by plain
+// ASCII, so that "m²" becomes "m2" and "s⁻¹" becomes "s-1". It is the inverse
+// of FormatSymbol for symbols without a caret.
+func FormatSymbolASCII(sym string) string {
+	return strings.Map(func(r rune) rune {
+		for plain, sup := range superscripts {
+			if r == sup {
+				return plain
+			}
+		}
+		return r
+	}, sym)
+}
+
+// A SymbolStyle selects how the unit symbols of a JSON response are written.
+type SymbolStyle int
+
+const (
+	SymbolAsIs        SymbolStyle = iota // symbols as defined, as written by ToJson.
+	SymbolSuperscript                    // exponents as superscripts by FormatSymbol, e.g. "m²".
+	SymbolASCII                          // exponents as plain digits by FormatSymbolASCII, e.g. "m2".
+)
+
+// formatSymbol returns sym written in style.
+func (style SymbolStyle) formatSymbol(sym string) string {
+	switch style {
+	case SymbolSuperscript:
+		return FormatSymbol(sym)
+	case SymbolASCII:
+		return FormatSymbolASCII(sym)
+	}
+	return sym
+}
+
+// JsonOptions controls the response written by ToJsonWith. The zero value
+// produces the same response as ToJson.
+type JsonOptions struct {
+	// Format adds the result formatted by FormatResult to the response.
+	Format bool
+	// Round adds the result rounded to Decimals decimals to the response.
+	Round bool
+	// Decimals is the number of decimals passed to FormatResult and used by
+	// Round. A negative Decimals does not round.
+	Decimals int
+	// Prefix and Indent, if either is set, indent the response as
+	// json.MarshalIndent does.
+	Prefix string
+	Indent string
+	// KeyStyle selects how the keys of the response are written.
+	KeyStyle KeyStyle
+	// Symbols selects how the unit symbols of the response are written.
+	Symbols SymbolStyle
+}
+
+// ToJsonWith converts val from the unit specified by from to the unit
+// specified by to and returns the result as a JSON formatted byte slice like
+// ToJson, adjusted as requested by opts.
+func ToJsonWith(val float64, from, to string, opts JsonOptions) ([]byte, error) {
+	return store.ToJsonWith(val, from, to, opts)
+}
+
+// ToJsonWith converts val from the unit specified by from to the unit
+// specified by to and returns the result as a JSON formatted byte slice like
+// ToJson, adjusted as requested by opts.
+func (s *Store) ToJsonWith(val float64, from, to string, opts JsonOptions) ([]byte, error) {
+	resp := s.newResponse(val, from, to)
+	if opts.Format && resp.Ok {
+		resp.Formatted = FormatResult(resp.Result, opts.Decimals)
+	}
+	if opts.Round && resp.Ok {
+		rounded := RoundResult(resp.Result, opts.Decimals)
+		resp.Rounded = &rounded
+	}
+	resp.FromSymbol = opts.Symbols.formatSymbol(resp.FromSymbol)
+	resp.ToSymbol = opts.Symbols.formatSymbol(resp.ToSymbol)
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	if opts.KeyStyle == KeySnake || opts.KeyStyle == KeyCamel {
+		if b, err = restyle(b, opts.KeyStyle); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Prefix != "" || opts.Indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, b, opts.Prefix, opts.Indent); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return b, nil
+}
+
+// ToJsonIndent converts val from the unit specified by from to the unit
+// specified by to and returns the same response as ToJson, indented with
+// prefix and indent as json.MarshalIndent does.
+func ToJsonIndent(val float64, from, to string, prefix, indent string) ([]byte, error) {
+	return store.ToJsonIndent(val, from, to, prefix, indent)
+}
+
+// ToJsonIndent converts val from the unit specified by from to the unit
+// specified by to and returns the same response as ToJson, indented with
+// prefix and indent as json.MarshalIndent does.
+func (s *Store) ToJsonIndent(val float64, from, to string, prefix, indent string) ([]byte, error) {
+	return s.ToJsonWith(val, from, to, JsonOptions{Prefix: prefix, Indent: indent})
+}
+
+// ToJsonRounded converts val from the unit specified by from to the unit
+// specified by to and returns the response of ToJson with the result rounded
+// to decimals digits added as "roundedresult", next to the exact "result".
+func ToJsonRounded(val float64, from, to string, decimals int) ([]byte, error) {
+	return store.ToJsonRounded(val, from, to, decimals)
+}
+
+// ToJsonRounded converts val from the unit specified by from to the unit
+// specified by to and returns the response of ToJson with the rounded result
+// added. See the package-level ToJsonRounded for details.
+func (s *Store) ToJsonRounded(val float64, from, to string, decimals int) ([]byte, error) {
+	return s.ToJsonWith(val, from, to, JsonOptions{Round: true, Decimals: decimals})
+}
+
+// ConvertString converts val from the unit specified by from to the unit
+// specified by to and returns the result formatted by FormatResult with
+// decimals digits, followed by a space and the symbol of to, e.g. "6.21 mi".
+// The name of to is used if it has no symbol.
+func ConvertString(val float64, from, to string, decimals int) (string, error) {
+	return store.ConvertString(val, from, to, decimals)
+}
+
+// ConvertString converts val from the unit specified by from to the unit
+// specified by to and returns the result formatted with its unit. See the
+// package-level ConvertString for details.
+func (s *Store) ConvertString(val float64, from, to string, decimals int) (string, error) {
+	v, err := s.ToValue(val, from, to)
+	if err != nil {
+		return "", err
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return "", Error(ErrUnknownUnit, to)
+	}
+	unit := t.Symbol()
+	if unit == "" {
+		unit = t.Name()
+	}
+	return FormatResult(v, decimals) + " " + unit, nil
+}
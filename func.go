@@ -0,0 +1,130 @@
+package convert
+
+import "strconv"
+
+// funcConverter implements Converter for units whose conversion to the base
+// UOM of their category is given by a pair of functions, e.g. logarithmic or
+// other nonlinear scales. An optional validity predicate restricts the values,
+// in the base UOM, that the unit accepts.
+type funcConverter struct {
+	name     string
+	symbol   string
+	baseuom  string
+	category string
+	toBase   func(float64) float64
+	fromBase func(float64) float64
+	valid    func(base float64) bool // optional; nil if every value is valid.
+}
+
+// FuncConverter returns a new funcConverter that converts values to its base
+// UOM with toBase and back with fromBase, which must be inverses of each other.
+func FuncConverter(name, symbol, baseunit, category string, toBase, fromBase func(float64) float64) (funcConverter, error) {
+	if name == "" || baseunit == "" || category == "" || toBase == nil || fromBase == nil {
+		return funcConverter{}, ErrMissingData
+	}
+
+	newUnit := funcConverter{
+		name:     name,
+		symbol:   symbol,
+		baseuom:  baseunit,
+		category: category,
+		toBase:   toBase,
+		fromBase: fromBase,
+	}
+	return newUnit, nil
+}
+
+// WithValidity returns a copy of the unit that only accepts the values for
+// which valid, called with the value in the base UOM, returns true, e.g. to
+// refuse temperatures below absolute zero.
+func (u funcConverter) WithValidity(valid func(base float64) bool) funcConverter {
+	u.valid = valid
+	return u
+}
+
+// Convert converts val from the unit defined in from to that defined in to,
+// which may be of any type that converts to and from its base UOM, and returns
+// the converted value and nil, or 0 and an error. ErrOutOfDomain is returned if
+// val, in the base UOM, is not valid for from or, if it is a funcConverter,
+// for to.
+func (from funcConverter) Convert(val float64, to Converter) (float64, error) {
+	if from.BaseUOM() != to.BaseUOM() || from.Category() != to.Category() {
+		return 0, ErrIncompatibleUnits
+	}
+
+	tto, ok := to.(BaseConverter)
+	if !ok {
+		return 0, ErrIncompatibleUnits
+	}
+	base := from.ConvertToBase(val)
+	if !from.accepts(base) {
+		return 0, Error(ErrOutOfDomain, strconv.FormatFloat(val, 'g', -1, 64)+" "+from.name)
+	}
+	if fto, ok := to.(funcConverter); ok && !fto.accepts(base) {
+		return 0, Error(ErrOutOfDomain, strconv.FormatFloat(val, 'g', -1, 64)+" "+from.name+" in "+fto.name)
+	}
+	return fromBase(tto, base)
+}
+
+// accepts reports whether base, a value in the base UOM, is valid for the unit.
+func (u funcConverter) accepts(base float64) bool {
+	return u.valid == nil || u.valid(base)
+}
+
+// ConvertToBase converts val from the unit to the base UOM of its category.
+func (u funcConverter) ConvertToBase(val float64) float64 {
+	return u.toBase(val)
+}
+
+// ConvertFromBase converts val from the base UOM of the unit's category to the
+// unit. It is the inverse of ConvertToBase.
+func (u funcConverter) ConvertFromBase(val float64) float64 {
+	return u.fromBase(val)
+}
+
+// Validate checks that the unit has a name, base unit, category and both
+// conversion functions.
+func (u funcConverter) Validate() error {
+	if u.name == "" || u.baseuom == "" || u.category == "" || u.toBase == nil || u.fromBase == nil {
+		return Error(ErrMissingData, u.name)
+	}
+	return nil
+}
+
+// Name returns the name of the unit.
+func (u funcConverter) Name() string {
+	return u.name
+}
+
+// Symbol returns the symbol of the unit.
+func (u funcConverter) Symbol() string {
+	return u.symbol
+}
+
+// Category returns the category of the unit Converter.
+func (u funcConverter) Category() string {
+	return u.category
+}
+
+// BaseUOM returns the base unit of the unit Converter.
+func (u funcConverter) BaseUOM() string {
+	return u.baseuom
+}
+
+// inCategory returns a copy of the unit Converter in category.
+func (u funcConverter) inCategory(category string) Converter {
+	u.category = category
+	return u
+}
+
+// withBase returns a copy of the unit Converter with base UOM baseuom.
+func (u funcConverter) withBase(baseuom string) Converter {
+	u.baseuom = baseuom
+	return u
+}
+
+// withName returns a copy of the unit Converter called name.
+func (u funcConverter) withName(name string) Converter {
+	u.name = name
+	return u
+}
@@ -0,0 +1,29 @@
+package convert
+
+import "math"
+
+// Number is the set of numeric types accepted by Convert.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Convert converts val from the unit specified by from to the unit specified
+// by to like ToValue, but for any numeric type. The conversion is done in
+// float64 and the result is converted back to T. Results for integer types are
+// rounded to the nearest integer, with halves rounded away from zero, rather
+// than truncated; results outside the range of T are undefined. Results for
+// float32 are subject to the usual float64 to float32 rounding.
+func Convert[T Number](val T, from, to string) (T, error) {
+	v, err := ToValue(float64(val), from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	half := 0.5
+	if T(half) == 0 { // T is an integer type.
+		v = math.Round(v)
+	}
+	return T(v), nil
+}
@@ -1,3 +1,8 @@
 module github.com/carlwf/convert
 
 go 1.23.5
+
+require (
+	github.com/BurntSushi/toml v1.5.0
+	golang.org/x/text v0.28.0
+)
@@ -0,0 +1,54 @@
+package convert
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Handler returns an http.Handler that serves conversions as JSON. See
+// ServeConvert for the request and response format.
+func Handler() http.Handler {
+	return store
+}
+
+// ServeConvert converts the value query parameter from the unit in the from
+// query parameter to the unit in the to query parameter and writes the result
+// in the same JSON format as ToJson. It responds with 200 OK on success and
+// with 400 Bad Request if value is missing or not a number or if the
+// conversion fails.
+func ServeConvert(w http.ResponseWriter, r *http.Request) {
+	store.ServeHTTP(w, r)
+}
+
+// ServeHTTP serves conversions between the units in the store as JSON, so a
+// Store can be used as an http.Handler. See ServeConvert for the request and
+// response format.
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var resp ConvertResponse
+	if v := q.Get("value"); v == "" {
+		err := Error(ErrMissingData, "value")
+		resp = ConvertResponse{Ok: false, Code: errorCode(err), Message: err.Error()}
+	} else if val, err := strconv.ParseFloat(v, 64); err != nil {
+		err := Error(ErrMalformedData, "value is not a number: "+v)
+		resp = ConvertResponse{Ok: false, Code: errorCode(err), Message: err.Error()}
+	} else {
+		resp, _ = s.Do(ConvertRequest{Value: val, From: q.Get("from"), To: q.Get("to")})
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	w.Write(body)
+}
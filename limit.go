@@ -0,0 +1,73 @@
+package convert
+
+import "strconv"
+
+// An OverflowPolicy selects what happens when a unit is added to a store that
+// holds the maximum number of units set with SetMaxUnits.
+type OverflowPolicy int
+
+const (
+	RejectWhenFull OverflowPolicy = iota // the new unit is rejected with ErrStoreFull.
+	EvictOldest                          // the unit registered first is removed to make room.
+)
+
+// SetMaxUnits limits the number of units the store holds to n, protecting
+// memory when units come from untrusted sources. Adding a unit that is not yet
+// in a full store is then handled as set by SetOverflowPolicy; replacing a
+// unit is always allowed. A zero or negative n removes the limit, which is the
+// default. Units already in the store are kept even if there are more than n.
+func SetMaxUnits(n int) {
+	store.SetMaxUnits(n)
+}
+
+// SetMaxUnits limits the number of units the store holds to n. See the
+// package-level SetMaxUnits for details.
+func (s *Store) SetMaxUnits(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxUnits = n
+}
+
+// SetOverflowPolicy selects what happens when a unit is added to a store that
+// is full; RejectWhenFull is the default.
+func SetOverflowPolicy(policy OverflowPolicy) {
+	store.SetOverflowPolicy(policy)
+}
+
+// SetOverflowPolicy selects what happens when a unit is added to the store
+// when it is full; RejectWhenFull is the default.
+func (s *Store) SetOverflowPolicy(policy OverflowPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overflow = policy
+}
+
+// makeRoom makes sure a new unit fits in the store, evicting the oldest units
+// if the overflow policy allows it. It returns ErrStoreFull otherwise. The
+// caller must hold the write lock.
+func (s *Store) makeRoom() error {
+	if s.maxUnits <= 0 {
+		return nil
+	}
+	for len(s.data) >= s.maxUnits {
+		if s.overflow != EvictOldest {
+			return Error(ErrStoreFull, "limit of "+strconv.Itoa(s.maxUnits)+" units")
+		}
+		s.drop(s.oldest())
+	}
+	return nil
+}
+
+// oldest returns the key of the unit registered first. The caller must hold
+// the lock.
+func (s *Store) oldest() string {
+	var key string
+	first := true
+	var min uint64
+	for k, seq := range s.order {
+		if first || seq < min {
+			key, min, first = k, seq, false
+		}
+	}
+	return key
+}
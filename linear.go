@@ -1,12 +1,25 @@
 package convert
 
 import (
+	"bufio"
+	"compress/gzip"
 	"encoding/json"
+	"io"
 	"os"
+	"strconv"
 )
 
 // linearConverter implements Converter and contains the logic and attributes for the
 // linear conversion of UOM.
+//
+// A value in the unit is converted to the base UOM of its category by first
+// multiplying it by factor and then adding offset:
+//
+//	base = value*factor + offset
+//
+// so, with Fahrenheit as the base UOM, Celsius has a factor of 1.8 and an
+// offset of 32. The factor may be negative for scales that run backwards
+// relative to the base UOM, but it cannot be zero.
 type linearConverter struct {
 	name     string
 	symbol   string
@@ -14,8 +27,16 @@ type linearConverter struct {
 	category string
 	factor   float64 // factor to convert to the base UOM for this category. cannot be 0 - protect in MakeLinearUOM.
 	offset   float64 // offset to convert to the base UOM for this category.
+
+	description string  // optional note describing the unit.
+	catdesc     string  // optional description of the unit's category.
+	domain      *Domain // optional physical limits in the base UOM; nil if unbounded.
+	exact       bool    // whether factor and offset are exact by definition.
 }
 
+// LinearConverter returns a linear unit with the given factor and offset to its
+// base UOM. It returns ErrMissingData if name, baseunit or category is empty
+// and ErrZeroNotAllowed if factor is zero; negative factors are allowed.
 func LinearConverter(name, symbol, baseunit, category string, factor, offset float64) (linearConverter, error) {
 	if name == "" || baseunit == "" || category == "" {
 		return linearConverter{}, ErrMissingData
@@ -35,6 +56,80 @@ func LinearConverter(name, symbol, baseunit, category string, factor, offset flo
 	return newUnit, nil
 }
 
+// Validate checks that the unit has a name, base unit and category and a
+// non-zero factor.
+func (u linearConverter) Validate() error {
+	if u.name == "" || u.baseuom == "" || u.category == "" {
+		return Error(ErrMissingData, u.name)
+	}
+	if u.factor == 0 {
+		return Error(ErrZeroNotAllowed, u.name)
+	}
+	return nil
+}
+
+// MustLinearConverter is like LinearConverter but panics if the unit is
+// invalid. It is intended for tables of known-good units, e.g. in init
+// functions and tests.
+func MustLinearConverter(name, symbol, baseunit, category string, factor, offset float64) linearConverter {
+	c, err := LinearConverter(name, symbol, baseunit, category, factor, offset)
+	if err != nil {
+		panic("convert: MustLinearConverter(" + strconv.Quote(name) + "): " + err.Error())
+	}
+	return c
+}
+
+// An Option modifies an attribute of a linear unit; see linearConverter.With.
+type Option func(*linearConverter)
+
+// WithName sets the name of the unit.
+func WithName(name string) Option {
+	return func(u *linearConverter) { u.name = name }
+}
+
+// WithSymbol sets the symbol of the unit.
+func WithSymbol(symbol string) Option {
+	return func(u *linearConverter) { u.symbol = symbol }
+}
+
+// WithCategory sets the category of the unit.
+func WithCategory(category string) Option {
+	return func(u *linearConverter) { u.category = category }
+}
+
+// WithBaseUOM sets the base UOM of the unit.
+func WithBaseUOM(baseuom string) Option {
+	return func(u *linearConverter) { u.baseuom = baseuom }
+}
+
+// WithFactor sets the factor that converts the unit to its base UOM.
+func WithFactor(factor float64) Option {
+	return func(u *linearConverter) { u.factor = factor }
+}
+
+// WithOffset sets the offset that converts the unit to its base UOM.
+func WithOffset(offset float64) Option {
+	return func(u *linearConverter) { u.offset = offset }
+}
+
+// WithDescription sets the note describing the unit.
+func WithDescription(description string) Option {
+	return func(u *linearConverter) { u.description = description }
+}
+
+// With returns a copy of the unit with opts applied in order; the unit itself
+// is not changed. It returns the error of Validate if the copy is invalid, e.g.
+// because its factor is zero.
+func (u linearConverter) With(opts ...Option) (linearConverter, error) {
+	for _, opt := range opts {
+		opt(&u)
+	}
+	if err := u.Validate(); err != nil {
+		return linearConverter{}, err
+	}
+	return u, nil
+}
+
 // Convert converts val from the converter type defined in from from to that defined
 // in to and returns the converted value and nil, or 0 and an error.
 func (from linearConverter) Convert(val float64, to Converter) (float64, error) {
@@ -42,12 +137,28 @@ func (from linearConverter) Convert(val float64, to Converter) (float64, error)
 		return 0, ErrIncompatibleUnits
 	}
 
-	tto, ok := to.(linearConverter)
+	tto, ok := to.(BaseConverter)
 	if !ok {
 		return 0, ErrIncompatibleUnits
 	}
-	return ((val*from.factor + from.offset) - tto.offset) / tto.factor, nil
-	// return ((val*from.factor + from.offset) - tto.offset) / from.factor, nil
+	return fromBase(tto, from.ConvertToBase(val))
+}
+
+// ConvertToBase converts val from the unit to the base UOM of its category,
+// returning val*factor + offset.
+func (u linearConverter) ConvertToBase(val float64) float64 {
+	return val*u.factor + u.offset
+}
+
+// ConvertFromBase converts val from the base UOM of the unit's category to the
+// unit, returning (val - offset) / factor. It is the inverse of ConvertToBase.
+func (u linearConverter) ConvertFromBase(val float64) float64 {
+	r := (val - u.offset) / u.factor
+	if r == 0 {
+		// a negative factor turns a zero difference into -0.
+		return 0
+	}
+	return r
 }
 
 // Name returns the name of the unit.
@@ -70,21 +181,107 @@ func (f linearConverter) BaseUOM() string {
 	return f.baseuom
 }
 
+// inCategory returns a copy of the unit Converter in category.
+func (u linearConverter) inCategory(category string) Converter {
+	u.category = category
+	return u
+}
+
+// withBase returns a copy of the unit Converter with base UOM baseuom.
+func (u linearConverter) withBase(baseuom string) Converter {
+	u.baseuom = baseuom
+	return u
+}
+
+// withName returns a copy of the unit Converter called name.
+func (u linearConverter) withName(name string) Converter {
+	u.name = name
+	return u
+}
+
+// Factor returns the factor that converts the unit to its base UOM.
+func (u linearConverter) Factor() float64 {
+	return u.factor
+}
+
+// Offset returns the offset that converts the unit to its base UOM.
+func (u linearConverter) Offset() float64 {
+	return u.offset
+}
+
+// Domain returns the physical limits of the unit in the base UOM of its
+// category, or Unbounded if it has none.
+func (u linearConverter) Domain() Domain {
+	if u.domain == nil {
+		return Unbounded
+	}
+	return *u.domain
+}
+
+// WithDomain returns a copy of the unit restricted to d, in the base UOM of its
+// category.
+func (u linearConverter) WithDomain(d Domain) linearConverter {
+	u.domain = &d
+	return u
+}
+
+// Exact reports whether the factor and offset of the unit are exact by
+// definition rather than rounded approximations.
+func (u linearConverter) Exact() bool {
+	return u.exact
+}
+
+// WithExact returns a copy of the unit marked as exact or not.
+func (u linearConverter) WithExact(exact bool) linearConverter {
+	u.exact = exact
+	return u
+}
+
+// MarshalJSON returns the JSON encoding of the unit, including its factor and
+// offset.
+func (u linearConverter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name     string  `json:"name"`
+		Symbol   string  `json:"symbol"`
+		Category string  `json:"category"`
+		BaseUOM  string  `json:"baseuom"`
+		Factor   float64 `json:"factor"`
+		Offset   float64 `json:"offset"`
+	}{u.name, u.symbol, u.category, u.baseuom, u.factor, u.offset})
+}
+
+// Description returns the optional note describing the unit.
+func (u linearConverter) Description() string {
+	return u.description
+}
+
+// CategoryDescription returns the optional description of the unit's category.
+func (u linearConverter) CategoryDescription() string {
+	return u.catdesc
+}
+
 // #
 // #
 // #
 // fileLayout represents the structure of json files that contains Converter data for linear UOMs.
 type fileLayout struct {
-	Category    string `json:"category"`
-	Description string `json:"description"`
-	BaseUnit    string `json:"baseunit"`
-	Units       []struct {
-		Name     string  `json:"name"`
-		Symbol   string  `json:"symbol"`
-		BaseUnit string  `json:"baseunit"`
-		Factor   float64 `json:"factor"`
-		Offset   float64 `json:"offset"`
-	} `json:"units"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	BaseUnit    string       `json:"baseunit"`
+	Min         *float64     `json:"min,omitempty"` // optional lower limit of the category in the base unit.
+	Max         *float64     `json:"max,omitempty"` // optional upper limit of the category in the base unit.
+	Units       []unitLayout `json:"units"`
+}
+
+// unitLayout represents a single unit in a fileLayout.
+type unitLayout struct {
+	Name        string  `json:"name"`
+	Symbol      string  `json:"symbol"`
+	Description string  `json:"description,omitempty"`
+	BaseUnit    string  `json:"baseunit,omitempty"`
+	Factor      float64 `json:"factor"`
+	Offset      float64 `json:"offset"`
+	Exact       bool    `json:"exact,omitempty"`
 }
 
 // LinUOMReader returns a new instance of fileLayout that can be used to read
@@ -93,23 +290,162 @@ func LinearReader() *fileLayout {
 	return new(fileLayout)
 }
 
+// ReadFile reads the linear UOMs in filename and returns them as Converters.
+// See Decode for the format of the file.
 func (fl *fileLayout) ReadFile(filename string) ([]Converter, error) {
-	var converters []Converter
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	err = json.NewDecoder(f).Decode(&fl)
-	if err != nil {
+	return fl.Decode(f)
+}
+
+// Decode reads the linear UOMs from r and returns them as Converters. The
+// data holds one fileLayout object or a sequence of them, one per category,
+// as written by ExportJSON, or a JSON array of such objects. Gzip-compressed
+// data is decompressed transparently.
+func (fl *fileLayout) Decode(r io.Reader) ([]Converter, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+
+	var converters []Converter
+	dec := json.NewDecoder(br)
+	if isArray(br) {
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			cs, err := fl.decodeNext(dec)
+			if err != nil {
+				return nil, err
+			}
+			converters = append(converters, cs...)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return converters, nil
+	}
+
+	for n := 0; ; n++ {
+		cs, err := fl.decodeNext(dec)
+		if err == io.EOF && n > 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		converters = append(converters, cs...)
+	}
+	return converters, nil
+}
+
+// decodeNext decodes the next fileLayout object from dec into fl and returns
+// its units as Converters.
+func (fl *fileLayout) decodeNext(dec *json.Decoder) ([]Converter, error) {
+	// reset so values from a previously read layout do not leak into this one.
+	*fl = fileLayout{}
+	if err := dec.Decode(fl); err != nil {
+		return nil, err
+	}
+	return fl.converters()
+}
+
+// isArray reports whether the first non-whitespace byte buffered in br opens
+// a JSON array. It does not consume any data.
+func isArray(br *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		b, err := br.Peek(n)
+		if len(b) < n {
+			return false
+		}
+		switch b[n-1] {
+		case ' ', '\t', '\n', '\r':
+			if err != nil {
+				return false
+			}
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// Extensions returns the file extensions read by a fileLayout.
+func (fl *fileLayout) Extensions() []string {
+	return []string{".json", ".json.gz"}
+}
+
+// validate checks that fl has a category and base unit, that its limits, if
+// given, are in order, that every unit has a name and a non-zero factor, and
+// that the base unit of every unit, if given, matches that of fl. The returned
+// error names the offending unit and field.
+func (fl *fileLayout) validate() error {
+	if fl.Category == "" {
+		return Error(ErrMissingData, "category")
+	}
+	if fl.BaseUnit == "" {
+		return Error(ErrMissingData, fl.Category+": baseunit")
+	}
+	if fl.Min != nil && fl.Max != nil && *fl.Min > *fl.Max {
+		return Error(ErrMalformedData, fl.Category+": min exceeds max")
+	}
+	for i, u := range fl.Units {
+		unit := fl.Category + ": unit " + strconv.Itoa(i+1)
+		if u.Name == "" {
+			return Error(ErrMissingData, unit+": name")
+		}
+		unit += " (" + u.Name + ")"
+		if u.Factor == 0 {
+			return Error(ErrZeroNotAllowed, unit+": factor")
+		}
+		if u.BaseUnit != "" && u.BaseUnit != fl.BaseUnit {
+			return Error(ErrMalformedData, unit+": baseunit "+u.BaseUnit+" does not match "+fl.BaseUnit)
+		}
+	}
+	return nil
+}
+
+// domain returns the Domain described by the limits of fl.
+func (fl *fileLayout) domain() Domain {
+	d := Unbounded
+	if fl.Min != nil {
+		d.Min = *fl.Min
+	}
+	if fl.Max != nil {
+		d.Max = *fl.Max
+	}
+	return d
+}
+
+// converters validates fl and returns its units as linear Converters.
+func (fl *fileLayout) converters() ([]Converter, error) {
+	if err := fl.validate(); err != nil {
 		return nil, err
 	}
+
+	var converters []Converter
 	for _, u := range fl.Units {
 		newUnit, err := LinearConverter(u.Name, u.Symbol, fl.BaseUnit, fl.Category, u.Factor, u.Offset)
 		if err != nil {
 			return nil, err
 		}
+		newUnit.description = u.Description
+		newUnit.catdesc = fl.Description
+		newUnit.exact = u.Exact
+		if fl.Min != nil || fl.Max != nil {
+			newUnit = newUnit.WithDomain(fl.domain())
+		}
 		converters = append(converters, newUnit)
 	}
 	return converters, nil
@@ -0,0 +1,66 @@
+package convert
+
+import (
+	"slices"
+	"strings"
+)
+
+// A MergePolicy selects how Merge resolves units defined in both stores.
+type MergePolicy int
+
+const (
+	MergeOverwrite    MergePolicy = iota // the unit of the other store replaces the existing one.
+	MergeKeepExisting                    // the existing unit is kept.
+	MergeError                           // nothing is merged if any unit conflicts.
+)
+
+// A Conflict is a unit defined in both stores passed to Merge.
+type Conflict struct {
+	Name     string
+	Existing Converter
+	Incoming Converter
+}
+
+// Merge adds/updates the Converters of other to/in s and returns the units,
+// matched by name ignoring case, that are defined in both, sorted by name.
+// policy selects how each conflict is resolved; with MergeError, s is left
+// unchanged if there is any conflict. The file a Converter was read from is
+// carried over with it. other is not changed. If s is full and rejects new
+// units, see SetMaxUnits, the units that do not fit are not merged.
+func (s *Store) Merge(other *Store, policy MergePolicy) []Conflict {
+	other.mu.RLock()
+	type entry struct {
+		c      Converter
+		source string
+	}
+	incoming := make(map[string]entry, len(other.data))
+	for key, c := range other.data {
+		incoming[key] = entry{c, other.sources[key]}
+	}
+	other.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	var conflicts []Conflict
+	for key, e := range incoming {
+		if old, ok := s.data[key]; ok {
+			conflicts = append(conflicts, Conflict{Name: e.c.Name(), Existing: old, Incoming: e.c})
+		}
+	}
+	slices.SortFunc(conflicts, func(a, b Conflict) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	if policy == MergeError && len(conflicts) > 0 {
+		return conflicts
+	}
+
+	for key, e := range incoming {
+		if _, ok := s.data[key]; ok && policy == MergeKeepExisting {
+			continue
+		}
+		// a unit rejected by a full store is skipped.
+		_ = s.put(e.c, e.source)
+	}
+	return conflicts
+}
@@ -0,0 +1,40 @@
+package convert
+
+// ToValueWith converts val from the unit specified by from to the unit
+// specified by to like ToValue, but looks the units up in overrides, matching
+// the keys ignoring case, before the store. This lets a caller redefine units,
+// e.g. the US gallon as the Imperial gallon, for a single conversion without
+// changing the store.
+func ToValueWith(val float64, from, to string, overrides map[string]Converter) (float64, error) {
+	return store.ToValueWith(val, from, to, overrides)
+}
+
+// ToValueWith converts val from the unit specified by from to the unit
+// specified by to, preferring the units in overrides. See the package-level
+// ToValueWith for details.
+func (s *Store) ToValueWith(val float64, from, to string, overrides map[string]Converter) (float64, error) {
+	if len(overrides) == 0 {
+		return s.ToValue(val, from, to)
+	}
+
+	byKey := make(map[string]Converter, len(overrides))
+	for name, c := range overrides {
+		byKey[unitKey(name)] = c
+	}
+	lookup := func(name string) (Converter, bool) {
+		if c, ok := byKey[unitKey(name)]; ok && c != nil {
+			return c, true
+		}
+		return s.Get(name)
+	}
+
+	f, ok := lookup(from)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, from)
+	}
+	t, ok := lookup(to)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, to)
+	}
+	return ConvertValue(val, f, t)
+}
@@ -0,0 +1,82 @@
+package convert
+
+// AddPairwise registers a direct conversion between the units specified by
+// fromName and toName, where one fromName equals factor toName. The inverse
+// conversion is registered as well. Both units must be in the store and in the
+// same category. ToValue uses these conversions, chaining as many as needed,
+// when the units cannot be converted through a common base UOM. Pairwise
+// conversions are purely multiplicative; offsets are not applied.
+func AddPairwise(fromName, toName string, factor float64) error {
+	return store.AddPairwise(fromName, toName, factor)
+}
+
+// AddPairwise registers a direct conversion between the units specified by
+// fromName and toName in the store. See the package-level AddPairwise for
+// details.
+func (s *Store) AddPairwise(fromName, toName string, factor float64) error {
+	if factor == 0 {
+		return ErrZeroNotAllowed
+	}
+	f, ok := s.Get(fromName)
+	if !ok {
+		return Error(ErrUnknownUnit, fromName)
+	}
+	t, ok := s.Get(toName)
+	if !ok {
+		return Error(ErrUnknownUnit, toName)
+	}
+	if f.Category() != t.Category() {
+		return ErrCategoryMismatch
+	}
+
+	s.addPair(unitKey(fromName), unitKey(toName), factor)
+	return nil
+}
+
+// addPair adds the edge from -> to with factor, and its inverse, to the
+// conversion graph of the store.
+func (s *Store) addPair(from, to string, factor float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+
+	if s.pairs[from] == nil {
+		s.pairs[from] = make(map[string]float64)
+	}
+	if s.pairs[to] == nil {
+		s.pairs[to] = make(map[string]float64)
+	}
+	s.pairs[from][to] = factor
+	s.pairs[to][from] = 1 / factor
+}
+
+// convertPairwise converts val from the unit from to the unit to by finding
+// the shortest path between them in the conversion graph of the store with a
+// breadth-first search. It returns false if no path exists or the units are
+// not in the same category.
+func (s *Store) convertPairwise(val float64, from, to Converter) (float64, bool) {
+	if from.Category() != to.Category() {
+		return 0, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	start, goal := unitKey(from.Name()), unitKey(to.Name())
+	factors := map[string]float64{start: 1}
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if name == goal {
+			return val * factors[name], true
+		}
+		for next, factor := range s.pairs[name] {
+			if _, seen := factors[next]; !seen {
+				factors[next] = factors[name] * factor
+				queue = append(queue, next)
+			}
+		}
+	}
+	return 0, false
+}
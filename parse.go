@@ -0,0 +1,108 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseAndConvert parses input, a number followed by a unit such as "10.5 km"
+// or "-40°F", and converts the number to the unit specified by to. Units are
+// matched by name and then by symbol, for both input and to. The number uses
+// "." as decimal separator and may group thousands with "," or spaces, e.g.
+// "1,000.5 m". ErrMalformedData is returned if input does not start with a
+// number.
+func ParseAndConvert(input, to string) (float64, error) {
+	return store.ParseAndConvertLocale(input, to, '.')
+}
+
+// ParseAndConvertLocale is like ParseAndConvert, but the number uses
+// decimalSep as decimal separator, e.g. ',' for "10,5 km". Thousands may be
+// grouped with spaces, apostrophes or, unless it is decimalSep, with the
+// other one of "." and ",", e.g. "1 000,5 km" or "1.000,5 km".
+func ParseAndConvertLocale(input, to string, decimalSep rune) (float64, error) {
+	return store.ParseAndConvertLocale(input, to, decimalSep)
+}
+
+// ParseAndConvertLocale parses input as a number followed by a unit and
+// converts the number to the unit specified by to. See the package-level
+// ParseAndConvertLocale for details.
+func (s *Store) ParseAndConvertLocale(input, to string, decimalSep rune) (float64, error) {
+	number, unit := splitQuantity(input, decimalSep)
+	if number == "" {
+		return 0, Error(ErrMalformedData, "no number in "+strconv.Quote(input))
+	}
+	if unit == "" {
+		return 0, Error(ErrMissingData, "no unit in "+strconv.Quote(input))
+	}
+
+	val, err := parseNumber(number, decimalSep)
+	if err != nil {
+		return 0, Error(ErrMalformedData, "not a number: "+number)
+	}
+	f, ok := s.resolve(unit)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, unit)
+	}
+	t, ok := s.resolve(to)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, to)
+	}
+	return s.ToValue(val, f.Name(), t.Name())
+}
+
+// resolve returns the unit specified by name, matching it by name and then by
+// symbol.
+func (s *Store) resolve(name string) (Converter, bool) {
+	if c, ok := s.Get(name); ok {
+		return c, true
+	}
+	return s.UnitBySymbol(name)
+}
+
+// isThousandsSep reports whether r groups thousands in numbers that use
+// decimalSep as decimal separator.
+func isThousandsSep(r, decimalSep rune) bool {
+	switch r {
+	case ' ', '\u00a0', '\u202f', '\'': // space, no-break space, narrow no-break space, apostrophe.
+		return true
+	case '.', ',':
+		return r != decimalSep
+	}
+	return false
+}
+
+// splitQuantity splits input into its leading number, as written with
+// decimalSep, and the trimmed unit that follows it.
+func splitQuantity(input string, decimalSep rune) (number, unit string) {
+	input = strings.TrimSpace(input)
+	end := 0
+	for i, r := range input {
+		switch {
+		case unicode.IsDigit(r), r == decimalSep, isThousandsSep(r, decimalSep):
+		case (r == '-' || r == '+') && i == 0:
+		default:
+			return strings.TrimRightFunc(input[:end], func(r rune) bool {
+				return isThousandsSep(r, decimalSep)
+			}), strings.TrimSpace(input[end:])
+		}
+		end = i + len(string(r))
+	}
+	return input, ""
+}
+
+// parseNumber parses number, written with decimalSep as decimal separator and
+// optional thousands separators.
+func parseNumber(number string, decimalSep rune) (float64, error) {
+	var b strings.Builder
+	for _, r := range number {
+		switch {
+		case r == decimalSep:
+			b.WriteByte('.')
+		case isThousandsSep(r, decimalSep):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strconv.ParseFloat(b.String(), 64)
+}
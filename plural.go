@@ -0,0 +1,38 @@
+package convert
+
+import "strings"
+
+// MatchPlurals enables plural unit names in lookups: when no unit matches a
+// name exactly, its singular forms are tried, so that "meters", "inches" and
+// "feet" find "meter", "inch" and "foot". It is off by default because the
+// rules are simple and can match a different unit than intended. It must be
+// set before units are looked up concurrently.
+var MatchPlurals bool
+
+// irregularPlurals maps plural unit names that the suffix rules of singulars
+// do not cover to their singular.
+var irregularPlurals = map[string]string{
+	"feet":  "foot",
+	"pence": "penny",
+}
+
+// singulars returns the candidate singular forms of name, most likely first.
+func singulars(name string) []string {
+	lower := strings.ToLower(name)
+	var cs []string
+	if s, ok := irregularPlurals[lower]; ok {
+		cs = append(cs, s)
+	}
+	// the longest stem comes first, so that "miles" finds "mile" rather than
+	// "mil"; "inches" falls through to the "es" rule.
+	if base, ok := strings.CutSuffix(lower, "s"); ok && base != "" {
+		cs = append(cs, base)
+	}
+	if base, ok := strings.CutSuffix(lower, "ies"); ok && base != "" {
+		cs = append(cs, base+"y")
+	}
+	if base, ok := strings.CutSuffix(lower, "es"); ok && base != "" {
+		cs = append(cs, base)
+	}
+	return cs
+}
@@ -0,0 +1,75 @@
+package convert
+
+import (
+	"math"
+	"strconv"
+)
+
+// precisionLimit is the magnitude ratio between two units above which a
+// conversion is flagged by ToValuePrecise. float64 carries about 15.9
+// significant decimal digits.
+const precisionLimit = 1e15
+
+// ToValuePrecise converts val from the unit specified by from to the unit
+// specified by to like ToValue and also reports whether the result has likely
+// lost significant digits. This is a heuristic: the conversion is flagged when
+// the ratio of the magnitudes of the two units, taken from their factors if
+// both have one and from the ratio of the result to val otherwise, exceeds
+// 1e15 in either direction, e.g. light-years to millimeters. The result is
+// returned either way.
+func ToValuePrecise(val float64, from, to string) (float64, bool, error) {
+	return store.ToValuePrecise(val, from, to)
+}
+
+// ToValuePrecise converts val from the unit specified by from to the unit
+// specified by to and reports whether the result has likely lost significant
+// digits. See the package-level ToValuePrecise for details.
+func (s *Store) ToValuePrecise(val float64, from, to string) (float64, bool, error) {
+	v, err := s.ToValue(val, from, to)
+	if err != nil {
+		return 0, false, err
+	}
+
+	type factorer interface{ Factor() float64 }
+	var ratio float64
+	f, _ := s.Get(from)
+	t, _ := s.Get(to)
+	ff, fok := f.(factorer)
+	tf, tok := t.(factorer)
+	switch {
+	case fok && tok:
+		ratio = ff.Factor() / tf.Factor()
+	case val != 0 && v != 0:
+		ratio = v / val
+	default:
+		return v, false, nil
+	}
+
+	ratio = math.Abs(ratio)
+	return v, ratio > precisionLimit || ratio < 1/precisionLimit, nil
+}
+
+// ToValueSigFigs converts val from the unit specified by from to the unit
+// specified by to like ToValue and rounds the result to sig significant
+// figures, so that both 0.00123456 and 123456 keep three digits as 0.00123 and
+// 123000 with a sig of 3. A zero result is returned as 0. ErrMalformedData is
+// returned if sig is less than 1.
+func ToValueSigFigs(val float64, from, to string, sig int) (float64, error) {
+	return store.ToValueSigFigs(val, from, to, sig)
+}
+
+// ToValueSigFigs converts val from the unit specified by from to the unit
+// specified by to and rounds the result to sig significant figures. See the
+// package-level ToValueSigFigs for details.
+func (s *Store) ToValueSigFigs(val float64, from, to string, sig int) (float64, error) {
+	if sig < 1 {
+		return 0, Error(ErrMalformedData, "significant figures: "+strconv.Itoa(sig))
+	}
+	v, err := s.ToValue(val, from, to)
+	if err != nil || v == 0 {
+		return 0, err
+	}
+
+	// round in decimal, so that e.g. 0.00123 is the closest float64 to 0.00123.
+	return strconv.ParseFloat(strconv.FormatFloat(v, 'e', sig-1, 64), 64)
+}
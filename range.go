@@ -0,0 +1,46 @@
+package convert
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// rangePattern matches two numbers separated by a dash or "to", e.g. "20-25",
+// "-40 to -20" or "1.5e3 - 2e3".
+var rangePattern = regexp.MustCompile(`^\s*([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*(?:-|–|to)\s*([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*$`)
+
+// ConvertRange parses input, a range of two numbers separated by a dash or
+// "to" such as "20-25" or "-40 to -20", and converts both endpoints from the
+// unit specified by from to the unit specified by to. The endpoints are
+// converted as absolute values with ToValue, so offsets apply; use
+// ToValueDelta for the width of a range. ErrMalformedData is returned if input
+// is not a range.
+func ConvertRange(input, from, to string) (low, high float64, err error) {
+	return store.ConvertRange(input, from, to)
+}
+
+// ConvertRange parses input as a range of two numbers and converts both
+// endpoints from the unit specified by from to the unit specified by to. See
+// the package-level ConvertRange for details.
+func (s *Store) ConvertRange(input, from, to string) (low, high float64, err error) {
+	m := rangePattern.FindStringSubmatch(input)
+	if m == nil {
+		return 0, 0, Error(ErrMalformedData, "not a range: "+input)
+	}
+	lo, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, 0, Error(ErrMalformedData, "not a range: "+input)
+	}
+	hi, err := strconv.ParseFloat(m[2], 64)
+	if err != nil {
+		return 0, 0, Error(ErrMalformedData, "not a range: "+input)
+	}
+
+	if low, err = s.ToValue(lo, from, to); err != nil {
+		return 0, 0, err
+	}
+	if high, err = s.ToValue(hi, from, to); err != nil {
+		return 0, 0, err
+	}
+	return low, high, nil
+}
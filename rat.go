@@ -0,0 +1,69 @@
+package convert
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// ToRat converts val from the unit specified by from to the unit specified by
+// to using exact rational arithmetic. The factor and offset of each unit are
+// taken as the shortest decimals that round to them, so that e.g. 25.4 is
+// exactly 254/10 and inches convert to millimeters without float64 drift.
+// Both units must be linear; ErrIncompatibleUnits is returned otherwise.
+// ErrMissingData is returned if val is nil.
+func ToRat(val *big.Rat, from, to string) (*big.Rat, error) {
+	return store.ToRat(val, from, to)
+}
+
+// ToRat converts val from the unit specified by from to the unit specified by
+// to using exact rational arithmetic. See the package-level ToRat for details.
+func (s *Store) ToRat(val *big.Rat, from, to string) (*big.Rat, error) {
+	if val == nil {
+		return nil, ErrMissingData
+	}
+	f, ok := s.Get(from)
+	if !ok {
+		return nil, Error(ErrUnknownUnit, from)
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return nil, Error(ErrUnknownUnit, to)
+	}
+	if f.BaseUOM() != t.BaseUOM() || f.Category() != t.Category() {
+		return nil, ErrIncompatibleUnits
+	}
+	lf, ok := f.(linearConverter)
+	if !ok {
+		return nil, Error(ErrIncompatibleUnits, from+" is not linear")
+	}
+	lt, ok := t.(linearConverter)
+	if !ok {
+		return nil, Error(ErrIncompatibleUnits, to+" is not linear")
+	}
+
+	ffac, foff, ok := lf.rats()
+	if !ok {
+		return nil, Error(ErrMalformedData, from)
+	}
+	tfac, toff, ok := lt.rats()
+	if !ok {
+		return nil, Error(ErrMalformedData, to)
+	}
+
+	// base = val*factor + offset; result = (base - offset) / factor.
+	r := new(big.Rat).Mul(val, ffac)
+	r.Add(r, foff)
+	r.Sub(r, toff)
+	return r.Quo(r, tfac), nil
+}
+
+// rats returns the factor and offset of u as big.Rats, or false if either is
+// not finite.
+func (u linearConverter) rats() (factor, offset *big.Rat, ok bool) {
+	factor, ok = new(big.Rat).SetString(strconv.FormatFloat(u.factor, 'g', -1, 64))
+	if !ok {
+		return nil, nil, false
+	}
+	offset, ok = new(big.Rat).SetString(strconv.FormatFloat(u.offset, 'g', -1, 64))
+	return factor, offset, ok
+}
@@ -0,0 +1,282 @@
+package convert
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+// A RateTable is a thread-safe table of named rates, each the value of one
+// unit of a reference expressed in a base UOM. Rate-based units are bound to
+// an entry of a RateTable, so that updating the entry with Set changes the
+// conversions of every unit bound to it at once, e.g. all units of a currency
+// basket or of an indexed unit.
+type RateTable struct {
+	mu   sync.RWMutex
+	data map[string]float64 // lowercased entry name -> rate.
+}
+
+// NewRateTable returns a new, empty RateTable.
+func NewRateTable() *RateTable {
+	return &RateTable{data: make(map[string]float64)}
+}
+
+// Set adds/updates the entry name, ignoring case, with rate. It returns
+// ErrZeroNotAllowed if rate is zero.
+func (t *RateTable) Set(name string, rate float64) error {
+	if rate == 0 {
+		return Error(ErrZeroNotAllowed, name)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.data[strings.ToLower(name)] = rate
+	return nil
+}
+
+// Rate returns the rate of the entry name, ignoring case, and whether it
+// exists.
+func (t *RateTable) Rate(name string) (float64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	r, ok := t.data[strings.ToLower(name)]
+	return r, ok
+}
+
+// rateConverter implements Converter for units, such as currencies, whose
+// factor to the base UOM changes over time. A unit bound to a RateTable reads
+// the factor from its entry every time a conversion is made; a unit created
+// with RateConverter carries its own rate, which SetRate replaces in the store.
+// As a BaseConverter, it converts into units of other types with the same
+// base UOM and category at the current rate.
+type rateConverter struct {
+	name     string
+	symbol   string
+	baseuom  string
+	category string
+
+	table *RateTable // nil if the unit carries its own rate in scale.
+	entry string     // lowercased name of the entry in table.
+	scale float64    // value of one unit in units of the entry, or in the base UOM if table is nil.
+}
+
+// RateConverter returns a new rateConverter with its initial rate, which is
+// the value of one unit expressed in the base UOM. The rate belongs to the
+// unit; once the unit is added to a store, it is updated there with SetRate.
+func RateConverter(name, symbol, baseunit, category string, rateToBase float64) (rateConverter, error) {
+	if name == "" || baseunit == "" || category == "" {
+		return rateConverter{}, ErrMissingData
+	}
+	if rateToBase == 0 {
+		return rateConverter{}, ErrZeroNotAllowed
+	}
+
+	newUnit := rateConverter{
+		name:     name,
+		symbol:   symbol,
+		baseuom:  baseunit,
+		category: category,
+		scale:    rateToBase,
+	}
+	return newUnit, nil
+}
+
+// BoundRateConverter returns a new rateConverter bound to the entry of table,
+// whose value is scale times the rate of the entry, so that e.g. a cent is
+// bound to the entry of the dollar with a scale of 0.01. The unit follows the
+// entry when it is updated with table.Set. It returns ErrUnknownUnit if table
+// has no such entry.
+func BoundRateConverter(name, symbol, baseunit, category string, table *RateTable, entry string, scale float64) (rateConverter, error) {
+	if name == "" || baseunit == "" || category == "" || table == nil {
+		return rateConverter{}, ErrMissingData
+	}
+	if scale == 0 {
+		return rateConverter{}, ErrZeroNotAllowed
+	}
+	if _, ok := table.Rate(entry); !ok {
+		return rateConverter{}, Error(ErrUnknownUnit, entry)
+	}
+
+	newUnit := rateConverter{
+		name:     name,
+		symbol:   symbol,
+		baseuom:  baseunit,
+		category: category,
+		table:    table,
+		entry:    strings.ToLower(entry),
+		scale:    scale,
+	}
+	return newUnit, nil
+}
+
+// SetRate updates the rate of the rate-based unit specified by unit in the
+// store. The rate is the value of one unit expressed in its base UOM.
+// Conversions use the new rate from their next lookup of the unit on. It
+// returns ErrUnknownUnit if the unit is unknown and ErrIncompatibleUnits if it
+// was not created with RateConverter; units bound to a RateTable with
+// BoundRateConverter are updated through the table.
+func SetRate(unit string, rateToBase float64) error {
+	return store.SetRate(unit, rateToBase)
+}
+
+// SetRate updates the rate of the rate-based unit specified by unit. See the
+// package-level SetRate for details.
+func (s *Store) SetRate(unit string, rateToBase float64) error {
+	if rateToBase == 0 {
+		return Error(ErrZeroNotAllowed, unit)
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	key := unitKey(unit)
+	c, ok := s.data[key]
+	if !ok {
+		return Error(ErrUnknownUnit, unit)
+	}
+	rc, ok := c.(rateConverter)
+	if !ok || rc.table != nil {
+		return Error(ErrIncompatibleUnits, unit+" does not carry its own rate")
+	}
+	rc.scale = rateToBase
+	s.put(rc, s.sources[key])
+	return nil
+}
+
+// rateLocked returns the current value of one unit in the base UOM. The caller
+// must hold the lock of the unit's table, if it has one.
+func (u rateConverter) rateLocked() (float64, error) {
+	if u.table == nil {
+		return u.scale, nil
+	}
+	r, ok := u.table.data[u.entry]
+	if !ok {
+		return 0, Error(ErrUnknownUnit, u.name)
+	}
+	return r * u.scale, nil
+}
+
+// rate returns the current value of one unit in the base UOM.
+func (u rateConverter) rate() (float64, error) {
+	if u.table == nil {
+		return u.scale, nil
+	}
+	u.table.mu.RLock()
+	defer u.table.mu.RUnlock()
+	return u.rateLocked()
+}
+
+// Validate checks that the unit has a name, base unit and category and a
+// non-zero rate.
+func (u rateConverter) Validate() error {
+	if u.name == "" || u.baseuom == "" || u.category == "" {
+		return Error(ErrMissingData, u.name)
+	}
+	if r, err := u.rate(); err != nil || r == 0 {
+		return Error(ErrZeroNotAllowed, u.name)
+	}
+	return nil
+}
+
+// ConvertToBase converts val from the unit to the base UOM of its category at
+// the current rate. It returns NaN if the unit's entry is missing from its
+// table.
+func (u rateConverter) ConvertToBase(val float64) float64 {
+	r, err := u.rate()
+	if err != nil {
+		return math.NaN()
+	}
+	return val * r
+}
+
+// ConvertFromBase converts val from the base UOM of the unit's category to the
+// unit at the current rate. It is the inverse of ConvertToBase.
+func (u rateConverter) ConvertFromBase(val float64) float64 {
+	r, err := u.rate()
+	if err != nil {
+		return math.NaN()
+	}
+	return val / r
+}
+
+// Convert converts val from the unit defined in from to that defined in to
+// using the current rates of both units and returns the converted value and
+// nil, or 0 and an error. If to is not a rate-based unit, val is converted
+// through the base UOM, which requires to to be a BaseConverter.
+func (from rateConverter) Convert(val float64, to Converter) (float64, error) {
+	if from.BaseUOM() != to.BaseUOM() || from.Category() != to.Category() {
+		return 0, ErrIncompatibleUnits
+	}
+
+	tto, ok := to.(rateConverter)
+	if !ok {
+		bc, ok := to.(BaseConverter)
+		if !ok {
+			return 0, ErrIncompatibleUnits
+		}
+		fr, err := from.rate()
+		if err != nil {
+			return 0, err
+		}
+		return bc.ConvertFromBase(val * fr), nil
+	}
+	var fr, tr float64
+	var ferr, terr error
+	if from.table != nil && from.table == tto.table {
+		// read both rates in one critical section so a concurrent update
+		// cannot be observed half applied.
+		from.table.mu.RLock()
+		fr, ferr = from.rateLocked()
+		tr, terr = tto.rateLocked()
+		from.table.mu.RUnlock()
+	} else {
+		fr, ferr = from.rate()
+		tr, terr = tto.rate()
+	}
+	if ferr != nil {
+		return 0, ferr
+	}
+	if terr != nil {
+		return 0, terr
+	}
+	return val * fr / tr, nil
+}
+
+// Name returns the name of the unit.
+func (u rateConverter) Name() string {
+	return u.name
+}
+
+// Symbol returns the symbol of the unit.
+func (u rateConverter) Symbol() string {
+	return u.symbol
+}
+
+// Category returns the category of the unit Converter.
+func (u rateConverter) Category() string {
+	return u.category
+}
+
+// BaseUOM returns the base unit of the unit Converter.
+func (u rateConverter) BaseUOM() string {
+	return u.baseuom
+}
+
+// inCategory returns a copy of the unit Converter in category.
+func (u rateConverter) inCategory(category string) Converter {
+	u.category = category
+	return u
+}
+
+// withBase returns a copy of the unit Converter with base UOM baseuom.
+func (u rateConverter) withBase(baseuom string) Converter {
+	u.baseuom = baseuom
+	return u
+}
+
+// withName returns a copy of the unit Converter called name, bound to the same
+// rate.
+func (u rateConverter) withName(name string) Converter {
+	u.name = name
+	return u
+}
@@ -0,0 +1,128 @@
+package convert
+
+// reciprocalConverter implements Converter for units that are inversely
+// proportional to the base UOM of their category, such as fuel economy in
+// miles per gallon against a base of liters per 100 kilometers. A value in the
+// unit is converted to the base UOM with
+//
+//	base = factor / value
+//
+// so that miles per gallon (US) has a factor of 235.215.
+type reciprocalConverter struct {
+	name     string
+	symbol   string
+	baseuom  string
+	category string
+	factor   float64
+}
+
+// ReciprocalConverter returns a new reciprocalConverter.
+func ReciprocalConverter(name, symbol, baseunit, category string, factor float64) (reciprocalConverter, error) {
+	if name == "" || baseunit == "" || category == "" {
+		return reciprocalConverter{}, ErrMissingData
+	}
+	if factor == 0 {
+		return reciprocalConverter{}, ErrZeroNotAllowed
+	}
+
+	newUnit := reciprocalConverter{
+		name:     name,
+		symbol:   symbol,
+		baseuom:  baseunit,
+		category: category,
+		factor:   factor,
+	}
+	return newUnit, nil
+}
+
+// Convert converts val from the unit defined in from to that defined in to,
+// which may be a linear or a reciprocal unit, and returns the converted value
+// and nil, or 0 and an error. A zero val has no reciprocal and is rejected
+// with ErrZeroNotAllowed; a zero value in the base UOM converted to a
+// reciprocal unit is rejected with ErrInvalidValue.
+func (from reciprocalConverter) Convert(val float64, to Converter) (float64, error) {
+	if from.BaseUOM() != to.BaseUOM() || from.Category() != to.Category() {
+		return 0, ErrIncompatibleUnits
+	}
+
+	tto, ok := to.(BaseConverter)
+	if !ok {
+		return 0, ErrIncompatibleUnits
+	}
+	if val == 0 {
+		return 0, ErrZeroNotAllowed
+	}
+	return fromBase(tto, from.ConvertToBase(val))
+}
+
+// fromBase converts base, a value in the base UOM, to the unit of to. It
+// returns ErrInvalidValue if to is a reciprocal unit and base is zero, which
+// has no reciprocal.
+func fromBase(to BaseConverter, base float64) (float64, error) {
+	if r, ok := to.(reciprocalConverter); ok && base == 0 {
+		return 0, Error(ErrInvalidValue, "0 "+r.baseuom+" has no reciprocal in "+r.name)
+	}
+	return to.ConvertFromBase(base), nil
+}
+
+// ConvertToBase converts val from the unit to the base UOM of its category,
+// returning factor / val.
+func (u reciprocalConverter) ConvertToBase(val float64) float64 {
+	return u.factor / val
+}
+
+// ConvertFromBase converts val from the base UOM of the unit's category to the
+// unit, returning factor / val. It is the inverse of ConvertToBase.
+func (u reciprocalConverter) ConvertFromBase(val float64) float64 {
+	return u.factor / val
+}
+
+// Validate checks that the unit has a name, base unit and category and a
+// non-zero factor.
+func (u reciprocalConverter) Validate() error {
+	if u.name == "" || u.baseuom == "" || u.category == "" {
+		return Error(ErrMissingData, u.name)
+	}
+	if u.factor == 0 {
+		return Error(ErrZeroNotAllowed, u.name)
+	}
+	return nil
+}
+
+// Name returns the name of the unit.
+func (u reciprocalConverter) Name() string {
+	return u.name
+}
+
+// Symbol returns the symbol of the unit.
+func (u reciprocalConverter) Symbol() string {
+	return u.symbol
+}
+
+// Category returns the category of the unit Converter.
+func (u reciprocalConverter) Category() string {
+	return u.category
+}
+
+// BaseUOM returns the base unit of the unit Converter.
+func (u reciprocalConverter) BaseUOM() string {
+	return u.baseuom
+}
+
+// inCategory returns a copy of the unit Converter in category.
+func (u reciprocalConverter) inCategory(category string) Converter {
+	u.category = category
+	return u
+}
+
+// withBase returns a copy of the unit Converter with base UOM baseuom.
+func (u reciprocalConverter) withBase(baseuom string) Converter {
+	u.baseuom = baseuom
+	return u
+}
+
+// withName returns a copy of the unit Converter called name.
+func (u reciprocalConverter) withName(name string) Converter {
+	u.name = name
+	return u
+}
@@ -0,0 +1,53 @@
+package convert
+
+import "errors"
+
+// ConvertRequest describes a conversion of Value from the unit specified by
+// From to the unit specified by To, e.g. as bound from the parameters of an
+// API request.
+type ConvertRequest struct {
+	Value float64 `json:"value"`
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+}
+
+// Do makes the conversion described by r and returns the response that ToJson
+// writes for it. If the conversion fails, the response reports the error,
+// which is also returned.
+func (r ConvertRequest) Do() (ConvertResponse, error) {
+	return store.Do(r)
+}
+
+// Do makes the conversion described by r between the units in the store. See
+// ConvertRequest.Do for details.
+func (s *Store) Do(r ConvertRequest) (ConvertResponse, error) {
+	f, _ := s.Get(r.From)
+	t, _ := s.Get(r.To)
+	result, err := s.ToValue(r.Value, r.From, r.To)
+	if err != nil {
+		resp := ConvertResponse{
+			Ok:      false,
+			Code:    errorCode(err),
+			Message: err.Error(),
+		}
+		// JSON has no representation for NaN and infinities.
+		if !errors.Is(err, ErrInvalidValue) {
+			resp.Value = r.Value
+		}
+		return resp, err
+	}
+
+	return ConvertResponse{
+		Ok:         true,
+		Message:    "success",
+		Value:      r.Value,
+		Result:     result,
+		Category:   t.Category(),
+		From:       r.From,
+		FromSymbol: f.Symbol(),
+		To:         r.To,
+		ToSymbol:   t.Symbol(),
+		BaseUOM:    t.BaseUOM(),
+		Exact:      isExact(f) && isExact(t),
+	}, nil
+}
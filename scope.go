@@ -0,0 +1,98 @@
+package convert
+
+import "maps"
+
+// WithConverters adds/updates cs to/in the store, calls fn and then restores
+// the store to its state before the call, even if fn panics. It is intended to
+// keep tests that register units from affecting each other:
+//
+//	convert.WithConverters(units, func() {
+//		// use units.
+//	})
+//
+// Changes made to the store while fn runs are undone as well. Conversions
+// running concurrently with fn see the scoped units. When the store is
+// restored, listeners registered with OnChange are sent a ChangeRemove or
+// ChangeAdd only for the units that differ from the scoped state.
+func WithConverters(cs []Converter, fn func()) {
+	store.WithConverters(cs, fn)
+}
+
+// WithConverters adds/updates cs to/in the store, calls fn and then restores
+// the store. See the package-level WithConverters for details.
+func (s *Store) WithConverters(cs []Converter, fn func()) {
+	saved := s.save()
+	defer s.restore(saved)
+
+	for _, c := range cs {
+		s.Add(c)
+	}
+	fn()
+}
+
+// storeState is a copy of the Converters and indexes of a Store.
+type storeState struct {
+	data    map[string]Converter
+	exact   map[string]map[string]Converter
+	symbols map[string]map[string]bool
+	pairs   map[string]map[string]float64
+	sources map[string]string
+	clashes map[string]error
+	order   map[string]uint64
+	seq     uint64
+}
+
+// save returns a copy of the Converters and indexes of the store.
+func (s *Store) save() storeState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := storeState{
+		data:    maps.Clone(s.data),
+		exact:   make(map[string]map[string]Converter, len(s.exact)),
+		symbols: make(map[string]map[string]bool, len(s.symbols)),
+		pairs:   make(map[string]map[string]float64, len(s.pairs)),
+		sources: maps.Clone(s.sources),
+		clashes: maps.Clone(s.clashes),
+		order:   maps.Clone(s.order),
+		seq:     s.seq,
+	}
+	for k, m := range s.exact {
+		st.exact[k] = maps.Clone(m)
+	}
+	for k, m := range s.symbols {
+		st.symbols[k] = maps.Clone(m)
+	}
+	for k, m := range s.pairs {
+		st.pairs[k] = maps.Clone(m)
+	}
+	return st
+}
+
+// restore replaces the Converters and indexes of the store with st. Listeners
+// are told only about the units that differ: those missing from st are
+// removed, and those new in st or defined differently, as by sameUnit, added.
+func (s *Store) restore(st storeState) {
+	s.mu.Lock()
+	defer s.unlock()
+
+	current := s.exact
+	s.data, s.exact, s.symbols, s.pairs, s.sources, s.clashes, s.order = st.data, st.exact, st.symbols, st.pairs, st.sources, st.clashes, st.order
+	s.seq = st.seq
+	s.hashes = make(map[string]map[string]string)
+	s.invalidate()
+	for key, spellings := range current {
+		for name, c := range spellings {
+			if _, ok := s.exact[key][name]; !ok {
+				s.record(ChangeEvent{Op: ChangeRemove, Name: c.Name()})
+			}
+		}
+	}
+	for key, spellings := range s.exact {
+		for name, c := range spellings {
+			if old, ok := current[key][name]; !ok || !sameUnit(old, c) {
+				s.record(ChangeEvent{Op: ChangeAdd, Name: c.Name()})
+			}
+		}
+	}
+}
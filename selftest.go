@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"math"
+	"slices"
+	"strconv"
+)
+
+// selfTestValues are the values round-tripped by SelfTest.
+var selfTestValues = []float64{1, 1000}
+
+// SelfTest checks that every unit in the store converts to the base UOM of its
+// category and back without changing the value by more than tolerance,
+// relative to the value. Where the base UOM is registered as a unit, the
+// conversion is also made through the store, like ToValue does, and must agree
+// with the unit's own conversion to its base UOM, and the base unit itself
+// must convert to its base UOM unchanged. It returns the problems found,
+// sorted by unit name: units that fail to convert and ErrMalformedData errors
+// for units whose conversions drift or disagree. Each unit is only tested
+// against its base UOM, so the cost grows linearly with the number of units.
+// It returns nil if no problems are found. A factor that is wrong but used
+// consistently cannot be detected.
+func SelfTest(tolerance float64) []error {
+	return store.SelfTest(tolerance)
+}
+
+// SelfTest checks that every unit in the store converts to the base UOM of its
+// category and back. See the package-level SelfTest for details.
+func (s *Store) SelfTest(tolerance float64) []error {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.data))
+	for _, c := range s.data {
+		names = append(names, c.Name())
+	}
+	s.mu.RUnlock()
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := s.selfTest(name, tolerance); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// selfTest checks the unit called name as described for SelfTest and returns
+// the first problem found.
+func (s *Store) selfTest(name string, tolerance float64) error {
+	drifts := func(got, want float64) bool {
+		return math.IsNaN(got) || math.Abs(got-want) > tolerance*math.Abs(want)
+	}
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	c, ok := s.Get(name)
+	if !ok {
+		return Error(ErrUnknownUnit, name)
+	}
+	base, hasBase := s.Get(c.BaseUOM())
+	hasBase = hasBase && base.BaseUOM() == c.BaseUOM() && base.Category() == c.Category()
+	isBase := hasBase && unitKey(base.Name()) == unitKey(name)
+
+	for _, val := range selfTestValues {
+		b, _, err := s.ToBase(val, name)
+		if err != nil {
+			return Error(err, name)
+		}
+		back, err := s.FromBase(b, name)
+		if err != nil {
+			return Error(err, name)
+		}
+		if drifts(back, val) {
+			return Error(ErrMalformedData, name+": "+format(val)+" round-trips to "+format(back))
+		}
+
+		switch {
+		case isBase:
+			if drifts(b, val) {
+				return Error(ErrMalformedData, name+": base unit converts "+format(val)+" to "+format(b))
+			}
+		case hasBase:
+			v, err := s.ToValue(val, name, base.Name())
+			if err != nil {
+				return Error(err, name)
+			}
+			if drifts(v, b) {
+				return Error(ErrMalformedData, name+": "+format(val)+" is "+format(v)+" "+base.Name()+" but "+format(b)+" in the base UOM")
+			}
+			back, err := s.ToValue(v, base.Name(), name)
+			if err != nil {
+				return Error(err, name)
+			}
+			if drifts(back, val) {
+				return Error(ErrMalformedData, name+": "+format(val)+" round-trips through "+base.Name()+" to "+format(back))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package convert
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Snapshot returns a copy of the Converters in the store, keyed by name.
+// Changing the store afterwards does not change the snapshot.
+func Snapshot() map[string]Converter {
+	return store.Snapshot()
+}
+
+// Snapshot returns a copy of the Converters in the store, keyed by name.
+func (s *Store) Snapshot() map[string]Converter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap := make(map[string]Converter, len(s.data))
+	for _, c := range s.data {
+		snap[c.Name()] = c
+	}
+	return snap
+}
+
+// A StoreDiff lists the names of the units that differ between two sets of
+// Converters, each sorted.
+type StoreDiff struct {
+	Added   []string // only in the new set.
+	Removed []string // only in the old set.
+	Changed []string // in both, but with a different definition.
+}
+
+// Diff compares two sets of Converters, such as those returned by Snapshot
+// before and after reloading, by unit name. A unit has changed if its type,
+// category, base UOM, factor, offset, description, category description or
+// Domain differs.
+func Diff(old, new map[string]Converter) StoreDiff {
+	var d StoreDiff
+	for name, c := range new {
+		o, ok := old[name]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, name)
+		case changed(o, c):
+			d.Changed = append(d.Changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+	slices.Sort(d.Changed)
+	return d
+}
+
+// changed reports whether a and b define a unit differently.
+func changed(a, b Converter) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || a.Category() != b.Category() || a.BaseUOM() != b.BaseUOM() {
+		return true
+	}
+
+	type factorer interface{ Factor() float64 }
+	type offsetter interface{ Offset() float64 }
+	if fa, ok := a.(factorer); ok && fa.Factor() != b.(factorer).Factor() {
+		return true
+	}
+	if oa, ok := a.(offsetter); ok && oa.Offset() != b.(offsetter).Offset() {
+		return true
+	}
+	if da, ok := a.(Describer); ok {
+		db := b.(Describer)
+		if da.Description() != db.Description() || da.CategoryDescription() != db.CategoryDescription() {
+			return true
+		}
+	}
+	if da, ok := a.(Domainer); ok && da.Domain() != b.(Domainer).Domain() {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,994 @@
+package convert
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Store is a thread-safe in-memory store for Converters. Stores are
+// independent of each other, so units registered in one Store are not visible
+// in another. The package-level functions operate on a default Store. The zero
+// value is an empty Store ready to use; NewStore returns one as well. A Store
+// must not be copied after first use.
+type Store struct {
+	mu      sync.RWMutex
+	data    map[string]Converter
+	exact   map[string]map[string]Converter // lowercased name -> each spelling of the name -> Converter.
+	symbols map[string]map[string]bool      // normalized symbol -> lowercased names.
+	pairs   map[string]map[string]float64   // conversion graph used by AddPairwise.
+
+	sources map[string]string            // lowercased name -> file the Converter was read from.
+	clashes map[string]error             // lowercased name -> definitions in several files; see Audit.
+	order   map[string]uint64            // lowercased name -> position in the order of registration.
+	hashes  map[string]map[string]string // file -> reader key -> content hash when it was last loaded by AddFromFiles.
+	seq     uint64                       // position of the next new Converter in order.
+	aliases map[string]string            // lowercased base UOM alias -> canonical base UOM.
+
+	maxUnits int            // maximum number of units; unlimited if <= 0. See SetMaxUnits.
+	overflow OverflowPolicy // what to do when adding to a full store.
+
+	cacheMu    sync.Mutex
+	linear     map[[2]string]linearUnits // unit keys -> compatible linear units; see linearPair.
+	categories []string                  // result of Categories; nil if not computed since the last change.
+
+	listeners []func(ChangeEvent) // registered with OnChange.
+	pending   []ChangeEvent       // recorded changes not yet delivered by unlock.
+}
+
+// NewStore returns a new, empty Store.
+func NewStore() *Store {
+	s := new(Store)
+	s.init()
+	return s
+}
+
+// init allocates the maps of a zero Store, so that it can be written to; the
+// maps of a zero Store can be read as they are. The caller must hold the write
+// lock unless s is not shared yet.
+func (s *Store) init() {
+	if s.data == nil {
+		s.data = make(map[string]Converter)
+	}
+	if s.exact == nil {
+		s.exact = make(map[string]map[string]Converter)
+	}
+	if s.symbols == nil {
+		s.symbols = make(map[string]map[string]bool)
+	}
+	if s.pairs == nil {
+		s.pairs = make(map[string]map[string]float64)
+	}
+	if s.sources == nil {
+		s.sources = make(map[string]string)
+	}
+	if s.clashes == nil {
+		s.clashes = make(map[string]error)
+	}
+	if s.order == nil {
+		s.order = make(map[string]uint64)
+	}
+	if s.hashes == nil {
+		s.hashes = make(map[string]map[string]string)
+	}
+	if s.aliases == nil {
+		s.aliases = make(map[string]string)
+	}
+}
+
+// store is the default Store used by the package-level functions.
+var store = NewStore()
+
+// ToValue converts val from the unit specified by from to the unit
+// specified by to. It returns the converted value and nil, or 0 and an error.
+// If the units cannot be converted through a common base UOM, ToValue falls
+// back to the conversions registered with AddPairwise.
+func (s *Store) ToValue(val float64, from, to string) (float64, error) {
+	v, err := s.toValue(val, from, to)
+	return converted(from, to, v, err)
+}
+
+// converted calls OnConvert, if set, for the conversion from the unit from to
+// the unit to that returned v and err, and returns them.
+func converted(from, to string, v float64, err error) (float64, error) {
+	if hook := OnConvert; hook != nil {
+		hook(from, to, err)
+	}
+	return v, err
+}
+
+// toValue implements ToValue without calling OnConvert.
+func (s *Store) toValue(val float64, from, to string) (float64, error) {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return 0, Error(ErrInvalidValue, strconv.FormatFloat(val, 'g', -1, 64))
+	}
+	if u, ok := s.linearPair(from, to); ok {
+		return ConvertValue(val, u.from, u.to)
+	}
+
+	f, ok := s.Get(from)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, from)
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, to)
+	}
+	v, err := ConvertValue(val, f, t)
+	if errors.Is(err, ErrIncompatibleUnits) {
+		if pv, ok := s.convertPairwise(val, f, t); ok {
+			return checkResult(pv, val, from, to)
+		}
+	}
+	return v, err
+}
+
+// ToValueSameBase converts val from the unit specified by from to the unit
+// specified by to like ToValue, but only requires both units to have the same
+// base UOM; their categories may differ. This allows conversions between units
+// that a dataset files under different categories of the same dimension.
+func (s *Store) ToValueSameBase(val float64, from, to string) (float64, error) {
+	v, err := s.toValueSameBase(val, from, to)
+	return converted(from, to, v, err)
+}
+
+// toValueSameBase implements ToValueSameBase without calling OnConvert.
+func (s *Store) toValueSameBase(val float64, from, to string) (float64, error) {
+	f, ok := s.Get(from)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, from)
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, to)
+	}
+	if f.BaseUOM() != t.BaseUOM() {
+		return 0, ErrIncompatibleUnits
+	}
+	if f.Category() != t.Category() {
+		r, ok := t.(recategorizer)
+		if !ok {
+			return 0, ErrCategoryMismatch
+		}
+		t = r.inCategory(f.Category())
+	}
+	return ConvertValue(val, f, t)
+}
+
+// ToValueCase converts val from the unit specified by from to the unit
+// specified by to like ToValue. If caseSensitive is true, the unit names must
+// match the case of the registered names exactly, so that e.g. "Mm" and "mm"
+// can be told apart; ErrUnknownUnit is returned otherwise.
+func (s *Store) ToValueCase(val float64, from, to string, caseSensitive bool) (float64, error) {
+	f, ok := s.getCase(from, caseSensitive)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, from)
+	}
+	t, ok := s.getCase(to, caseSensitive)
+	if !ok {
+		return 0, Error(ErrUnknownUnit, to)
+	}
+	return ConvertValue(val, f, t)
+}
+
+// Compatible reports whether the units specified by from and to share a
+// category and base UOM and can therefore be converted into each other. It
+// returns an error only if either unit is unknown.
+func (s *Store) Compatible(from, to string) (bool, error) {
+	f, ok := s.Get(from)
+	if !ok {
+		return false, Error(ErrUnknownUnit, from)
+	}
+	t, ok := s.Get(to)
+	if !ok {
+		return false, Error(ErrUnknownUnit, to)
+	}
+	return f.Category() == t.Category() && f.BaseUOM() == t.BaseUOM(), nil
+}
+
+// ToJson converts val from the unit specified by from to the unit specified by
+// to and returns the result as a JSON formatted byte slice with information
+// about the conversion.
+func (s *Store) ToJson(val float64, from, to string) ([]byte, error) {
+	return json.Marshal(s.newResponse(val, from, to))
+}
+
+// newResponse converts val from the unit specified by from to the unit
+// specified by to and returns the response describing the conversion.
+func (s *Store) newResponse(val float64, from, to string) ConvertResponse {
+	resp, _ := s.Do(ConvertRequest{Value: val, From: from, To: to})
+	return resp
+}
+
+// AddFromFiles adds/updates the Converters read by reader from the files
+// matching the glob pattern path to/in the store.
+func (s *Store) AddFromFiles(reader ConverterReader, path string) error {
+	return s.AddFromFilesContext(context.Background(), reader, path)
+}
+
+// AddFromFilesContext is like AddFromFiles but stops before reading the next
+// file and returns ctx.Err() once ctx is done. Converters from files that were
+// read before ctx was done remain in the store.
+func (s *Store) AddFromFilesContext(ctx context.Context, reader ConverterReader, path string) error {
+	return s.addFromFiles(ctx, reader, path, nil)
+}
+
+// AddFromFilesExcluding adds/updates the Converters read by reader from the
+// files matching path but none of the patterns in exclude to/in the store. See
+// the package-level AddFromFilesExcluding for details.
+func (s *Store) AddFromFilesExcluding(reader ConverterReader, path string, exclude []string) error {
+	for _, pattern := range exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+	return s.addFromFiles(context.Background(), reader, path, exclude)
+}
+
+// excluded reports whether file, or its base name, matches any of the
+// patterns in exclude.
+func excluded(file string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if ok, _ := filepath.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addFromFiles implements AddFromFilesContext and AddFromFilesExcluding.
+func (s *Store) addFromFiles(ctx context.Context, reader ConverterReader, path string, exclude []string) (err error) {
+	files, err := filepath.Glob(path)
+	if err != nil {
+		return err
+	}
+
+	// read every file first and then insert everything that was read, even when
+	// stopping early, under a single acquisition of the lock.
+	var batch []loadedFile
+	defer func() {
+		if lerr := s.addLoaded(batch); err == nil {
+			err = lerr
+		}
+	}()
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if canRead(reader, file) && !excluded(file, exclude) {
+			via := readerKey(reader)
+			same, hash := s.unchanged(file, via)
+			if same {
+				continue
+			}
+			cs, err := reader.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			batch = append(batch, loadedFile{file: file, cs: cs, hash: hash, via: via})
+		}
+	}
+	return nil
+}
+
+// AddFromDir adds/updates the Converters read by reader from the files below
+// root to/in the store. See the package-level AddFromDir for details.
+func (s *Store) AddFromDir(reader ConverterReader, root string) (err error) {
+	var batch []loadedFile
+	defer func() {
+		if lerr := s.addLoaded(batch); err == nil {
+			err = lerr
+		}
+	}()
+
+	return filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && file != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !canRead(reader, file) {
+			return nil
+		}
+		cs, err := reader.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, loadedFile{file: file, cs: cs})
+		return nil
+	})
+}
+
+// ReloadFromFiles replaces the Converters in the store with those read by
+// reader from the files matching path. See the package-level ReloadFromFiles
+// for details.
+func (s *Store) ReloadFromFiles(reader ConverterReader, path string) error {
+	files, err := filepath.Glob(path)
+	if err != nil {
+		return err
+	}
+
+	var batch []loadedFile
+	for _, file := range files {
+		if canRead(reader, file) {
+			cs, err := reader.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			batch = append(batch, loadedFile{file: file, cs: cs})
+		}
+	}
+
+	// build the new indexes off to the side and swap them in at once.
+	fresh := NewStore()
+	s.mu.RLock()
+	maps.Copy(fresh.aliases, s.aliases)
+	fresh.maxUnits, fresh.overflow = s.maxUnits, s.overflow
+	s.mu.RUnlock()
+	if err := fresh.addLoaded(batch); err != nil {
+		return err
+	}
+	s.restore(fresh.save())
+	return nil
+}
+
+// loadedFile holds the Converters read from a file.
+type loadedFile struct {
+	file string
+	cs   []Converter
+	hash string // sha256 of the file's content; empty if not computed.
+	via  string // key of the reader that read the file; see readerKey.
+}
+
+// addLoaded adds/updates the Converters read from files to/in the store under
+// a single acquisition of the lock, recording the file each was read from.
+func (s *Store) addLoaded(files []loadedFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	s.init()
+	for _, f := range files {
+		seen := make(map[string]bool)
+		for _, c := range f.cs {
+			c = s.canonicalBase(c)
+			key := unitKey(c.Name())
+			s.noteClash(key, c, f.file, seen[key])
+			seen[key] = true
+			if old, ok := s.data[key]; ok && s.sources[key] == f.file && sameUnit(old, c) {
+				continue
+			}
+			if err := s.put(c, f.file); err != nil {
+				return Error(err, f.file)
+			}
+		}
+		if f.hash != "" {
+			if s.hashes[f.file] == nil {
+				s.hashes[f.file] = make(map[string]string)
+			}
+			s.hashes[f.file][f.via] = f.hash
+		}
+	}
+	return nil
+}
+
+// noteClash records, for Audit, whether c, about to be loaded from file,
+// replaces a unit with the same name read from another file, or one read
+// earlier from file itself if again is true. The caller must hold the write
+// lock.
+func (s *Store) noteClash(key string, c Converter, file string, again bool) {
+	delete(s.clashes, key)
+	old, ok := s.data[key]
+	switch {
+	case again:
+		s.clashes[key] = Error(ErrMalformedData, c.Name()+": defined more than once in "+file)
+	case ok && s.sources[key] != "" && s.sources[key] != file && old.Category() != c.Category():
+		s.clashes[key] = Error(ErrDuplicateUnit, c.Name()+": "+old.Category()+" in "+s.sources[key]+", "+c.Category()+" in "+file)
+	case ok && s.sources[key] != "" && s.sources[key] != file:
+		s.clashes[key] = Error(ErrMalformedData, c.Name()+": defined in "+s.sources[key]+" and "+file)
+	}
+}
+
+// unchanged reports whether the content of file hashes to the same value as
+// when the store last loaded it with a reader with key via, and returns the
+// hash. It returns false if the file cannot be read; the reader reports the
+// error then.
+func (s *Store) unchanged(file, via string) (bool, string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, ""
+	}
+	sum := sha256.Sum256(data)
+	hash := string(sum[:])
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hashes[file][via] == hash, hash
+}
+
+// readerKey returns a key that tells reader apart from readers that read the
+// same file differently, including the lookup normalizer in use, so that a
+// file is only skipped as unchanged if it would be read the same way again.
+func readerKey(reader ConverterReader) string {
+	var key string
+	switch r := reader.(type) {
+	case *fileLayout, *tomlLayout:
+		key = fmt.Sprintf("%T", r)
+	case *csvLayout:
+		key = fmt.Sprintf("%T %q %q", r, r.category, r.baseunit)
+	default:
+		key = fmt.Sprintf("%T %#v", r, r)
+	}
+
+	if v := reflect.ValueOf(LookupNormalizer); v.Kind() == reflect.Func {
+		return key + fmt.Sprintf(" %T %x", LookupNormalizer, v.Pointer())
+	}
+	return key + fmt.Sprintf(" %T %#v", LookupNormalizer, LookupNormalizer)
+}
+
+// sameUnit reports whether a and b define the same unit, by the criteria of
+// Diff and by name and symbol.
+func sameUnit(a, b Converter) bool {
+	return a.Name() == b.Name() && a.Symbol() == b.Symbol() && isExact(a) == isExact(b) && !changed(a, b)
+}
+
+// RegisterBaseAlias records aliases as alternative spellings of the base UOM
+// canonical, e.g. "metre" for "meter". Converters read by AddFromFiles
+// afterwards whose base UOM matches an alias, ignoring case, adopt canonical
+// as their base UOM, so units from files that spell the base UOM differently
+// can be converted into each other. Converters already in the store are not
+// changed.
+func (s *Store) RegisterBaseAlias(canonical string, aliases ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+	for _, alias := range aliases {
+		s.aliases[strings.ToLower(alias)] = canonical
+	}
+	// files read before may be read differently now.
+	s.hashes = make(map[string]map[string]string)
+}
+
+// canonicalBase returns c with its base UOM replaced by the canonical base UOM
+// registered for it with RegisterBaseAlias, if any. The caller must hold the
+// lock.
+func (s *Store) canonicalBase(c Converter) Converter {
+	canonical, ok := s.aliases[strings.ToLower(c.BaseUOM())]
+	if !ok || canonical == c.BaseUOM() {
+		return c
+	}
+	if r, ok := c.(rebaser); ok {
+		return r.withBase(canonical)
+	}
+	return c
+}
+
+// Get retrieves a Converter from the store based on the provided name, or its
+// singular if MatchPlurals is set. It returns the Converter and a boolean
+// indicating whether it was found in the store.
+func (s *Store) Get(name string) (Converter, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if c, ok := s.data[unitKey(name)]; ok {
+		return c, true
+	}
+	if MatchPlurals {
+		for _, singular := range singulars(name) {
+			if c, ok := s.data[unitKey(singular)]; ok {
+				return c, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// getCase retrieves a Converter from the store like Get, but matches the case
+// of name exactly if caseSensitive is true.
+func (s *Store) getCase(name string, caseSensitive bool) (Converter, bool) {
+	if !caseSensitive {
+		return s.Get(name)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.exact[unitKey(name)][normalize(name)]
+	return c, ok
+}
+
+// put adds/updates a Converter to/in the indexes of the store and records the
+// file it was read from, if any. It returns ErrStoreFull if a new unit does
+// not fit in the store; replacing a unit never fails. The caller must hold the
+// write lock.
+func (s *Store) put(c Converter, source string) error {
+	s.init()
+	key := unitKey(c.Name())
+	name := normalize(c.Name())
+	if old, ok := s.data[key]; ok && normalize(old.Name()) != name {
+		// a unit whose name differs only in case from the one registered
+		// first is kept next to it for case-sensitive lookups.
+		s.exact[key][name] = c
+		s.invalidate()
+		s.record(ChangeEvent{Op: ChangeAdd, Name: c.Name()})
+		return nil
+	}
+	if old, ok := s.data[key]; ok {
+		s.dropSymbol(old, key)
+		// the file the unit was read from no longer matches the store.
+		delete(s.hashes, s.sources[key])
+	} else {
+		if err := s.makeRoom(); err != nil {
+			return err
+		}
+		s.order[key] = s.seq
+		s.seq++
+	}
+	s.data[key] = c
+	if s.exact[key] == nil {
+		s.exact[key] = make(map[string]Converter)
+	}
+	s.exact[key][name] = c
+	if sym := normalize(c.Symbol()); sym != "" {
+		if s.symbols[sym] == nil {
+			s.symbols[sym] = make(map[string]bool)
+		}
+		s.symbols[sym][key] = true
+	}
+	if source != "" {
+		s.sources[key] = source
+	} else {
+		delete(s.sources, key)
+	}
+	s.invalidate()
+	s.record(ChangeEvent{Op: ChangeAdd, Name: c.Name()})
+	return nil
+}
+
+// Add adds/updates a Converter to/in the store. It returns ErrStoreFull if
+// the store is full; see SetMaxUnits.
+func (s *Store) Add(c Converter) error {
+	s.mu.Lock()
+	defer s.unlock()
+	return s.put(c, "")
+}
+
+// Source returns the file the Converter specified by name was read from by
+// AddFromFiles. It returns false if the unit is unknown or was not read from a
+// file.
+func (s *Store) Source(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	src, ok := s.sources[unitKey(name)]
+	return src, ok
+}
+
+// RegisterAll adds/updates all Converters in cs to/in the store under a single
+// acquisition of the lock. Every Converter is validated first: if any is nil
+// or has no name, RegisterAll returns an error identifying the first such
+// Converter and the store is left unchanged.
+func (s *Store) RegisterAll(cs []Converter) error {
+	for i, c := range cs {
+		if c == nil || c.Name() == "" {
+			return Error(ErrMissingData, "converter "+strconv.Itoa(i)+" has no name")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	if s.maxUnits > 0 && s.overflow == RejectWhenFull {
+		added := make(map[string]bool)
+		for _, c := range cs {
+			if key := unitKey(c.Name()); s.data[key] == nil {
+				added[key] = true
+			}
+		}
+		if len(s.data)+len(added) > s.maxUnits {
+			return Error(ErrStoreFull, "limit of "+strconv.Itoa(s.maxUnits)+" units")
+		}
+	}
+	for _, c := range cs {
+		s.put(c, "")
+	}
+	return nil
+}
+
+// AddStrict adds/updates a Converter to/in the store like Add, but returns
+// ErrDuplicateUnit instead of replacing a Converter with the same name in a
+// different category.
+func (s *Store) AddStrict(c Converter) error {
+	s.mu.Lock()
+	defer s.unlock()
+
+	key := unitKey(c.Name())
+	if old, ok := s.data[key]; ok && old.Category() != c.Category() {
+		return Error(ErrDuplicateUnit, c.Name()+" ("+old.Category()+")")
+	}
+	return s.put(c, "")
+}
+
+// UpdateFactor replaces the factor and offset of the linear unit specified by
+// name. See the package-level UpdateFactor for details.
+func (s *Store) UpdateFactor(name string, factor, offset float64) error {
+	if factor == 0 {
+		return Error(ErrZeroNotAllowed, name)
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	key := unitKey(name)
+	c, ok := s.data[key]
+	if !ok {
+		return Error(ErrUnknownUnit, name)
+	}
+	lc, ok := c.(linearConverter)
+	if !ok {
+		return Error(ErrIncompatibleUnits, name+" is not linear")
+	}
+	// the new factor is not known to be exact.
+	lc.factor, lc.offset, lc.exact = factor, offset, false
+	s.put(lc, s.sources[key])
+	return nil
+}
+
+// Rename renames the unit specified by oldName to newName. See the
+// package-level Rename for details.
+func (s *Store) Rename(oldName, newName string) error {
+	if newName == "" {
+		return Error(ErrMissingData, "new name for "+oldName)
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	oldKey, newKey := unitKey(oldName), unitKey(newName)
+	c, ok := s.data[oldKey]
+	if !ok {
+		return Error(ErrUnknownUnit, oldName)
+	}
+	if other, ok := s.data[newKey]; ok && newKey != oldKey && other.Category() != c.Category() {
+		return Error(ErrDuplicateUnit, newName+" ("+other.Category()+")")
+	}
+	r, ok := c.(renamer)
+	if !ok {
+		return Error(ErrIncompatibleUnits, oldName+" cannot be renamed")
+	}
+
+	source, order, edges := s.sources[oldKey], s.order[oldKey], s.pairs[oldKey]
+	s.drop(oldKey)
+	s.drop(newKey)
+	s.put(r.withName(newName), source)
+	s.order[newKey] = order
+	for next, factor := range edges {
+		if next == newKey {
+			continue
+		}
+		if s.pairs[newKey] == nil {
+			s.pairs[newKey] = make(map[string]float64)
+		}
+		s.pairs[newKey][next] = factor
+		s.pairs[next][newKey] = 1 / factor
+	}
+	return nil
+}
+
+// Remove removes a Converter from the store based on the provided name. If the
+// Converter is not in the store, it does nothing.
+func (s *Store) Remove(name string) {
+	s.mu.Lock()
+	defer s.unlock()
+	s.drop(unitKey(name))
+}
+
+// RemoveCategory removes all Converters whose category matches category,
+// ignoring case, from the store and returns how many were removed.
+func (s *Store) RemoveCategory(category string) int {
+	s.mu.Lock()
+	defer s.unlock()
+
+	n := 0
+	for key, c := range s.data {
+		if strings.EqualFold(c.Category(), category) {
+			s.drop(key)
+			n++
+		}
+	}
+	return n
+}
+
+// drop removes the Converter stored under key from all indexes of the store.
+// The caller must hold the write lock.
+func (s *Store) drop(key string) {
+	if c, ok := s.data[key]; ok {
+		s.dropSymbol(c, key)
+		s.invalidate()
+		s.record(ChangeEvent{Op: ChangeRemove, Name: c.Name()})
+	}
+	delete(s.hashes, s.sources[key])
+	delete(s.data, key)
+	delete(s.exact, key)
+	delete(s.sources, key)
+	delete(s.clashes, key)
+	delete(s.order, key)
+	for next := range s.pairs[key] {
+		delete(s.pairs[next], key)
+	}
+	delete(s.pairs, key)
+}
+
+// Clear removes all Converters from the store.
+func (s *Store) Clear() {
+	s.mu.Lock()
+	defer s.unlock()
+	s.data = make(map[string]Converter)
+	s.exact = make(map[string]map[string]Converter)
+	s.symbols = make(map[string]map[string]bool)
+	s.pairs = make(map[string]map[string]float64)
+	s.sources = make(map[string]string)
+	s.clashes = make(map[string]error)
+	s.order = make(map[string]uint64)
+	s.hashes = make(map[string]map[string]string)
+	s.invalidate()
+	s.record(ChangeEvent{Op: ChangeClear})
+}
+
+// Categories returns a list of all categories of Converters in the store.
+func (s *Store) Categories() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if s.categories == nil {
+		s.categories = s.sortedCategories()
+	}
+	return slices.Clone(s.categories)
+}
+
+// sortedCategories returns the categories of the Converters in the store,
+// sorted as described for Categories. The caller must hold the lock.
+func (s *Store) sortedCategories() []string {
+	categories := make(map[string]bool)
+	for _, c := range s.data {
+		categories[c.Category()] = true
+	}
+
+	result := make([]string, 0, len(categories))
+	for c := range categories {
+		result = append(result, c)
+	}
+
+	slices.SortFunc(result, func(a, b string) int {
+		return cmp.Or(strings.Compare(strings.ToLower(a), strings.ToLower(b)), strings.Compare(a, b))
+	})
+
+	return result
+}
+
+// CategoriesWithCounts returns the number of Converters in each category in
+// the store, keyed by category.
+func (s *Store) CategoriesWithCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, c := range s.data {
+		counts[c.Category()]++
+	}
+	return counts
+}
+
+// Overview returns a CategoryInfo for every category in the store. See the
+// package-level Overview for details.
+func (s *Store) Overview() []CategoryInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bases := make(map[string]map[string]bool)
+	infos := make(map[string]*CategoryInfo)
+	for _, c := range s.data {
+		info, ok := infos[c.Category()]
+		if !ok {
+			info = &CategoryInfo{Category: c.Category(), BaseUOM: c.BaseUOM()}
+			infos[c.Category()] = info
+			bases[c.Category()] = make(map[string]bool)
+		}
+		info.UnitCount++
+		bases[c.Category()][c.BaseUOM()] = true
+	}
+
+	overview := make([]CategoryInfo, 0, len(infos))
+	for _, category := range s.sortedCategories() {
+		info := infos[category]
+		if len(bases[category]) > 1 {
+			info.BaseUOM = ""
+			info.BaseUOMs = slices.Sorted(maps.Keys(bases[category]))
+		}
+		overview = append(overview, *info)
+	}
+	return overview
+}
+
+// UnitsByCategory returns the units in category sorted by name.
+func (s *Store) UnitsByCategory(category string) []Uom {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	units := make([]Uom, 0, len(s.data))
+	for _, c := range s.data {
+		if c.Category() == category {
+			units = append(units, newUom(c))
+		}
+	}
+
+	slices.SortFunc(units, func(a, b Uom) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return units
+}
+
+// CompatibleUnits returns the other units with the same base UOM as the unit
+// specified by name, in any category, sorted by name. See the package-level
+// CompatibleUnits for details.
+func (s *Store) CompatibleUnits(name string) ([]Uom, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := unitKey(name)
+	u, ok := s.data[key]
+	if !ok {
+		return nil, Error(ErrUnknownUnit, name)
+	}
+
+	var units []Uom
+	for k, c := range s.data {
+		if k != key && c.BaseUOM() == u.BaseUOM() {
+			units = append(units, newUom(c))
+		}
+	}
+
+	slices.SortFunc(units, func(a, b Uom) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return units, nil
+}
+
+// FilterConverters returns the Converters in the store for which pred returns
+// true, sorted by name. See the package-level FilterConverters for details.
+func (s *Store) FilterConverters(pred func(Converter) bool) []Converter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var cs []Converter
+	for _, c := range s.data {
+		if pred(c) {
+			cs = append(cs, c)
+		}
+	}
+
+	slices.SortFunc(cs, func(a, b Converter) int {
+		return strings.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name()))
+	})
+
+	return cs
+}
+
+// UnitsByCategorySorted returns the units in category sorted by the key
+// specified by by. See the package-level UnitsByCategorySorted for details.
+func (s *Store) UnitsByCategorySorted(category string, by SortKey) []Uom {
+	type factorer interface{ Factor() float64 }
+
+	s.mu.RLock()
+	cs := make([]Converter, 0, len(s.data))
+	order := make(map[string]uint64)
+	for key, c := range s.data {
+		if c.Category() == category {
+			cs = append(cs, c)
+			order[key] = s.order[key]
+		}
+	}
+	s.mu.RUnlock()
+
+	byName := func(a, b Converter) int {
+		return strings.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name()))
+	}
+	slices.SortFunc(cs, func(a, b Converter) int {
+		switch by {
+		case SortBySymbol:
+			return cmp.Or(strings.Compare(a.Symbol(), b.Symbol()), byName(a, b))
+		case SortByFactor:
+			af, aok := a.(factorer)
+			bf, bok := b.(factorer)
+			switch {
+			case aok && bok:
+				return cmp.Or(cmp.Compare(af.Factor(), bf.Factor()), byName(a, b))
+			case aok:
+				return -1
+			case bok:
+				return 1
+			}
+		case SortByInsertion:
+			return cmp.Compare(order[unitKey(a.Name())], order[unitKey(b.Name())])
+		}
+		return byName(a, b)
+	})
+
+	units := make([]Uom, 0, len(cs))
+	for _, c := range cs {
+		units = append(units, newUom(c))
+	}
+	return units
+}
+
+// UnitsByCategoryOrdered returns the units in category in the order in which
+// they were first registered.
+func (s *Store) UnitsByCategoryOrdered(category string) []Uom {
+	return s.UnitsByCategorySorted(category, SortByInsertion)
+}
+
+// CategoryDescription returns the description of category as provided by the
+// Converters registered in it, or an empty string if none carries one.
+func (s *Store) CategoryDescription(category string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, desc := "", ""
+	for k, c := range s.data {
+		d, ok := c.(Describer)
+		if !ok || c.Category() != category || d.CategoryDescription() == "" {
+			continue
+		}
+		// pick the lowest key so the result does not depend on map order.
+		if desc == "" || k < key {
+			key, desc = k, d.CategoryDescription()
+		}
+	}
+	return desc
+}
+
+// BaseUOM returns the base UOM shared by the units in category. See the
+// package-level BaseUOM for details.
+func (s *Store) BaseUOM(category string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var bases []string
+	for _, c := range s.data {
+		if c.Category() == category && !slices.Contains(bases, c.BaseUOM()) {
+			bases = append(bases, c.BaseUOM())
+		}
+	}
+
+	switch len(bases) {
+	case 0:
+		return "", Error(ErrUnknownUnit, "no units in category "+category)
+	case 1:
+		return bases[0], nil
+	}
+	slices.Sort(bases)
+	return "", Error(ErrInconsistentCategory, category+": "+strings.Join(bases, ", "))
+}
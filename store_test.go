@@ -0,0 +1,1408 @@
+package convert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// writeFile writes data to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuditReportsNameClashes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `{"category": "Volume", "baseunit": "cubic meter", "units": [
+		{"name": "cubic meter", "factor": 1},
+		{"name": "liter", "factor": 0.001}]}`)
+	writeFile(t, dir, "b.json", `{"category": "Volume (dry)", "baseunit": "liter", "units": [
+		{"name": "liter", "factor": 1},
+		{"name": "peck", "factor": 8.8},
+		{"name": "peck", "factor": 8.8}]}`)
+
+	s := NewStore()
+	if err := s.AddFromFiles(LinearReader(), filepath.Join(dir, "*.json")); err != nil {
+		t.Fatal(err)
+	}
+	errs := s.Audit()
+	if len(errs) != 2 {
+		t.Fatalf("Audit() = %v; want 2 problems", errs)
+	}
+	if !errors.Is(errs[0], ErrDuplicateUnit) {
+		t.Errorf("Audit()[0] = %v; want ErrDuplicateUnit for liter", errs[0])
+	}
+	if !errors.Is(errs[1], ErrMalformedData) {
+		t.Errorf("Audit()[1] = %v; want ErrMalformedData for peck", errs[1])
+	}
+}
+
+func TestReloadFromFilesKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `{"category": "Length", "baseunit": "a1", "units": [
+		{"name": "a1", "factor": 1}, {"name": "a2", "factor": 2},
+		{"name": "a3", "factor": 3}, {"name": "a4", "factor": 4}]}`)
+
+	s := NewStore()
+	if err := s.ReloadFromFiles(LinearReader(), filepath.Join(dir, "*.json")); err != nil {
+		t.Fatal(err)
+	}
+	s.Add(MustLinearConverter("b1", "", "a1", "Length", 5, 0))
+	s.Add(MustLinearConverter("b2", "", "a1", "Length", 6, 0))
+
+	var names []string
+	for _, u := range s.UnitsByCategoryOrdered("Length") {
+		names = append(names, u.Name)
+	}
+	if want := []string{"a1", "a2", "a3", "a4", "b1", "b2"}; !slices.Equal(names, want) {
+		t.Errorf("UnitsByCategoryOrdered = %v; want %v", names, want)
+	}
+}
+
+func TestAddFromFilesRereadsWithOtherReaderOrAlias(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "a.csv", "metre,m,1\nfoot,ft,0.3048\n")
+
+	s := NewStore()
+	s.Add(MustLinearConverter("yard", "yd", "meter", "Length", 0.9144, 0))
+	if err := s.AddFromFiles(CSVReader("Size", "metre"), path); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddFromFiles(CSVReader("Length", "metre"), path); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := s.Get("foot"); c.Category() != "Length" {
+		t.Errorf("foot in category %q after reading with another reader; want Length", c.Category())
+	}
+
+	s.RegisterBaseAlias("meter", "metre")
+	if err := s.AddFromFiles(CSVReader("Length", "metre"), path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.ToValue(1, "yard", "foot")
+	if err != nil || !approx(got, 3) {
+		t.Errorf("ToValue(1, yard, foot) = %v, %v; want 3", got, err)
+	}
+}
+
+func TestAddFromFilesRereadsChangedDescriptionAndDomain(t *testing.T) {
+	dir := t.TempDir()
+	const layout = `{"category": "Length", "description": %q, "baseunit": "meter", %s"units": [
+		{"name": "meter", "symbol": "m", "description": %q, "factor": 1},
+		{"name": "foot", "symbol": "ft", "factor": 0.3048}]}`
+	path := writeFile(t, dir, "length.json", fmt.Sprintf(layout, "Distances", "", "SI unit"))
+
+	s := NewStore()
+	if err := s.AddFromFiles(LinearReader(), path); err != nil {
+		t.Fatal(err)
+	}
+	var events []ChangeEvent
+	s.OnChange(func(e ChangeEvent) { events = append(events, e) })
+	reload := func(min, meterDesc string) []ChangeEvent {
+		t.Helper()
+		events = nil
+		writeFile(t, dir, "length.json", fmt.Sprintf(layout, "Distances", min, meterDesc))
+		if err := s.AddFromFiles(LinearReader(), path); err != nil {
+			t.Fatal(err)
+		}
+		slices.SortFunc(events, func(a, b ChangeEvent) int { return strings.Compare(a.Name, b.Name) })
+		return events
+	}
+
+	if got, want := reload("", "the SI unit"), []ChangeEvent{{ChangeAdd, "meter"}}; !slices.Equal(got, want) {
+		t.Errorf("events after editing a description = %v; want %v", got, want)
+	}
+	if c, _ := s.Get("meter"); c.(Describer).Description() != "the SI unit" {
+		t.Errorf("description of meter = %q; want the edited one", c.(Describer).Description())
+	}
+
+	got := reload(`"min": 0, `, "the SI unit")
+	if want := []ChangeEvent{{ChangeAdd, "foot"}, {ChangeAdd, "meter"}}; !slices.Equal(got, want) {
+		t.Errorf("events after editing the min = %v; want %v", got, want)
+	}
+	if _, err := s.ToValueChecked(-1, "foot", "meter"); !errors.Is(err, ErrOutOfDomain) {
+		t.Errorf("ToValueChecked(-1, foot, meter) error = %v; want ErrOutOfDomain", err)
+	}
+}
+
+func TestZeroStore(t *testing.T) {
+	var s Store
+	if _, err := s.ToValue(1, "foot", "meter"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToValue on an empty Store = %v; want ErrUnknownUnit", err)
+	}
+	if err := s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	s.RegisterBaseAlias("meter", "metre")
+	if err := s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPairwise("foot", "meter", 0.3048); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.ToValue(1, "foot", "meter")
+	if err != nil || !approx(got, 0.3048) {
+		t.Errorf("ToValue(1, foot, meter) = %v, %v; want 0.3048", got, err)
+	}
+
+	var cleared Store
+	cleared.Clear()
+	cleared.RegisterBaseAlias("meter", "metre")
+	if err := cleared.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// skewedFoot is a foot whose Convert disagrees with its ConvertToBase by a
+// factor of ten.
+type skewedFoot struct{ linearConverter }
+
+func (f skewedFoot) Convert(val float64, to Converter) (float64, error) {
+	return f.linearConverter.Convert(10*val, to)
+}
+
+func TestSelfTest(t *testing.T) {
+	meter := MustLinearConverter("meter", "m", "meter", "Length", 1, 0)
+	foot := MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0)
+
+	tests := []struct {
+		name  string
+		units []Converter
+		bad   string
+	}{
+		{"consistent", []Converter{meter, foot}, ""},
+		{"base unit not identity", []Converter{MustLinearConverter("meter", "m", "meter", "Length", 1.0001, 0), foot}, "meter"},
+		{"conversion disagrees with base", []Converter{meter, skewedFoot{foot}}, "foot"},
+	}
+	for _, tt := range tests {
+		s := NewStore()
+		if err := s.RegisterAll(tt.units); err != nil {
+			t.Fatal(err)
+		}
+		errs := s.SelfTest(1e-9)
+		if tt.bad == "" {
+			if errs != nil {
+				t.Errorf("%s: SelfTest = %v; want nil", tt.name, errs)
+			}
+			continue
+		}
+		found := slices.ContainsFunc(errs, func(err error) bool {
+			return errors.Is(err, ErrMalformedData) && strings.HasPrefix(err.Error(), "malformed data: "+tt.bad+":")
+		})
+		if !found {
+			t.Errorf("%s: SelfTest = %v; want ErrMalformedData for %s", tt.name, errs, tt.bad)
+		}
+	}
+	if errs := newDefaultStore(t).SelfTest(1e-9); errs != nil {
+		t.Errorf("SelfTest of the defaults = %v; want nil", errs)
+	}
+}
+
+func TestToValueCaseVariants(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("Mm", "Mm", "meter", "Length", 1e6, 0))
+	s.Add(MustLinearConverter("mm", "mm", "meter", "Length", 0.001, 0))
+
+	if got, err := s.ToValueCase(1, "Mm", "meter", true); err != nil || got != 1e6 {
+		t.Errorf("ToValueCase(1, Mm, meter, true) = %v, %v; want 1e6", got, err)
+	}
+	if got, err := s.ToValueCase(1, "mm", "meter", true); err != nil || got != 0.001 {
+		t.Errorf("ToValueCase(1, mm, meter, true) = %v, %v; want 0.001", got, err)
+	}
+	if got, err := s.ToValueCase(1, "Mm", "mm", true); err != nil || got != 1e9 {
+		t.Errorf("ToValueCase(1, Mm, mm, true) = %v, %v; want 1e9", got, err)
+	}
+	if _, err := s.ToValueCase(1, "MM", "meter", true); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToValueCase(1, MM, meter, true) error = %v; want ErrUnknownUnit", err)
+	}
+	// case-insensitive lookups return the spelling registered first.
+	if got, err := s.ToValue(1, "mm", "meter"); err != nil || got != 1e6 {
+		t.Errorf("ToValue(1, mm, meter) = %v, %v; want 1e6", got, err)
+	}
+
+	s.Add(MustLinearConverter("mm", "mm", "meter", "Length", 0.0011, 0))
+	if got, err := s.ToValueCase(1, "mm", "meter", true); err != nil || got != 0.0011 {
+		t.Errorf("ToValueCase(1, mm, meter, true) after update = %v, %v; want 0.0011", got, err)
+	}
+	if got, err := s.ToValueCase(1, "Mm", "meter", true); err != nil || got != 1e6 {
+		t.Errorf("ToValueCase(1, Mm, meter, true) after update = %v, %v; want 1e6", got, err)
+	}
+}
+
+func TestBaseUOM(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("gram", "g", "gram", "Mass", 1, 0))
+	s.Add(MustLinearConverter("pound", "lb", "pound", "Mass", 1, 0))
+
+	if got, err := s.BaseUOM("Length"); err != nil || got != "meter" {
+		t.Errorf("BaseUOM(Length) = %q, %v; want meter", got, err)
+	}
+	if _, err := s.BaseUOM("Mass"); !errors.Is(err, ErrInconsistentCategory) {
+		t.Errorf("BaseUOM(Mass) error = %v; want ErrInconsistentCategory", err)
+	}
+	if _, err := s.BaseUOM("Time"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("BaseUOM(Time) error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestToValueSameBase(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Distance", 0.3048, 0))
+
+	var calls int
+	OnConvert = func(from, to string, err error) { calls++ }
+	defer func() { OnConvert = nil }()
+
+	if got, err := s.ToValueSameBase(1, "foot", "meter"); err != nil || got != 0.3048 {
+		t.Errorf("ToValueSameBase(1, foot, meter) = %v, %v; want 0.3048", got, err)
+	}
+	if got, err := s.ToValueSameBase(math.NaN(), "foot", "meter"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("ToValueSameBase(NaN, foot, meter) = %v, %v; want ErrInvalidValue", got, err)
+	}
+	if got, err := s.ToValueSameBase(1e308, "meter", "foot"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("ToValueSameBase(1e308, meter, foot) = %v, %v; want ErrInvalidValue", got, err)
+	}
+	if got, _ := s.ToValueSameBase(0, "foot", "meter"); math.Signbit(got) {
+		t.Errorf("ToValueSameBase(0, foot, meter) = %v; want +0", got)
+	}
+	if calls != 4 {
+		t.Errorf("OnConvert called %d times; want 4", calls)
+	}
+}
+
+func TestUpdateFactorClearsExact(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0).WithExact(true))
+	s.Add(MustLinearConverter("inch", "in", "meter", "Length", 0.0254, 0).WithExact(true))
+	if err := s.UpdateFactor("inch", 0.025, 0); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := s.Get("inch")
+	if c.(Exacter).Exact() {
+		t.Error("inch is exact after UpdateFactor; want not exact")
+	}
+	b, err := s.ToJson(1, "inch", "meter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), `"exact"`) {
+		t.Errorf("ToJson = %s; want no exact flag", b)
+	}
+}
+
+func TestExportImportJSON(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0))
+	var buf bytes.Buffer
+	if err := s.ExportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	t2 := NewStore()
+	if err := t2.ImportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := t2.ToValue(10, "foot", "meter"); err != nil || !approx(got, 3.048) {
+		t.Errorf("ToValue(10, foot, meter) after ImportJSON = %v, %v; want 3.048", got, err)
+	}
+	if err := t2.ImportJSON(strings.NewReader(`{"type": "nosuchtype"}`)); !errors.Is(err, ErrMalformedData) {
+		t.Errorf("ImportJSON(unknown type) error = %v; want ErrMalformedData", err)
+	}
+}
+
+func TestAddStrict(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("pound", "lb", "kilogram", "Mass", 0.45359237, 0))
+	err := s.AddStrict(MustLinearConverter("pound", "lbf", "newton", "Force", 4.4482216, 0))
+	if !errors.Is(err, ErrDuplicateUnit) {
+		t.Errorf("AddStrict(pound in Force) error = %v; want ErrDuplicateUnit", err)
+	}
+	if c, _ := s.Get("pound"); c.Category() != "Mass" {
+		t.Errorf("pound is in %s after a rejected AddStrict; want Mass", c.Category())
+	}
+	if err := s.AddStrict(MustLinearConverter("Pound", "lb", "kilogram", "Mass", 0.4536, 0)); err != nil {
+		t.Errorf("AddStrict(pound in Mass): %v", err)
+	}
+}
+
+func TestCategoriesWithCounts(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0))
+	s.Add(MustLinearConverter("gram", "g", "gram", "Mass", 1, 0))
+	got := s.CategoriesWithCounts()
+	if want := map[string]int{"Length": 2, "Mass": 1}; !maps.Equal(got, want) {
+		t.Errorf("CategoriesWithCounts() = %v; want %v", got, want)
+	}
+}
+
+func TestAddPairwise(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("cup", "", "cup", "Kitchen", 1, 0))
+	s.Add(MustLinearConverter("spoon", "", "spoon", "Kitchen", 1, 0))
+	s.Add(MustLinearConverter("drop", "", "drop", "Kitchen", 1, 0))
+	if _, err := s.ToValue(1, "cup", "spoon"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Fatalf("ToValue(cup, spoon) error = %v; want ErrIncompatibleUnits", err)
+	}
+	if err := s.AddPairwise("cup", "spoon", 16); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPairwise("spoon", "drop", 100); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "cup", "drop"); err != nil || got != 1600 {
+		t.Errorf("ToValue(1, cup, drop) = %v, %v; want 1600", got, err)
+	}
+	if got, err := s.ToValue(32, "spoon", "cup"); err != nil || got != 2 {
+		t.Errorf("ToValue(32, spoon, cup) = %v, %v; want 2", got, err)
+	}
+}
+
+func TestTOMLReader(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "mass.toml", `category = "Mass"
+baseunit = "kilogram"
+
+[[units]]
+name = "kilogram"
+symbol = "kg"
+factor = 1
+
+[[units]]
+name = "pound"
+symbol = "lb"
+factor = 0.45359237
+`)
+
+	s := NewStore()
+	if err := s.AddFromFiles(TOMLReader(), filepath.Join(dir, "*.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "pound", "kilogram"); err != nil || got != 0.45359237 {
+		t.Errorf("ToValue(1, pound, kilogram) = %v, %v; want 0.45359237", got, err)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "length.csv", "name,symbol,factor,offset\nmeter,m,1\nfoot,ft,0.3048,\n")
+
+	s := NewStore()
+	if err := s.AddFromFiles(CSVReader("Length", "meter"), filepath.Join(dir, "*.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "foot", "meter"); err != nil || got != 0.3048 {
+		t.Errorf("ToValue(1, foot, meter) = %v, %v; want 0.3048", got, err)
+	}
+
+	_, err := CSVReader("Length", "meter").Decode(strings.NewReader("meter,m,1\nfoot,ft,x\n"))
+	if !errors.Is(err, ErrMalformedData) || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Decode(invalid factor) error = %v; want ErrMalformedData at line 2", err)
+	}
+}
+
+// cancelReader reads files with LinearReader and cancels its context after
+// the first file.
+type cancelReader struct {
+	cancel context.CancelFunc
+	read   []string
+}
+
+func (r *cancelReader) ReadFile(filename string) ([]Converter, error) {
+	r.read = append(r.read, filepath.Base(filename))
+	r.cancel()
+	return LinearReader().ReadFile(filename)
+}
+
+func TestAddFromFilesContextCancel(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `{"category": "Length", "baseunit": "meter", "units": [{"name": "meter", "factor": 1}]}`)
+	writeFile(t, dir, "b.json", `{"category": "Length", "baseunit": "meter", "units": [{"name": "foot", "factor": 0.3048}]}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &cancelReader{cancel: cancel}
+	s := NewStore()
+	err := s.AddFromFilesContext(ctx, r, filepath.Join(dir, "*.json"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("AddFromFilesContext error = %v; want context.Canceled", err)
+	}
+	if want := []string{"a.json"}; !slices.Equal(r.read, want) {
+		t.Errorf("files read = %v; want %v", r.read, want)
+	}
+	if _, ok := s.Get("meter"); !ok {
+		t.Error("meter, read before the cancellation, is not in the store")
+	}
+	if _, ok := s.Get("foot"); ok {
+		t.Error("foot, in a file after the cancellation, is in the store")
+	}
+}
+
+func TestToValueChain(t *testing.T) {
+	s := newDefaultStore(t)
+	if got, err := s.ToValueChain(1, "mile", "yard", "foot"); err != nil || !approx(got, 5280) {
+		t.Errorf("ToValueChain(1, mile, yard, foot) = %v, %v; want 5280", got, err)
+	}
+	if _, err := s.ToValueChain(1, "mile", "kilogram", "foot"); !errors.Is(err, ErrIncompatibleUnits) || !strings.Contains(err.Error(), "step 1") {
+		t.Errorf("ToValueChain(1, mile, kilogram, foot) error = %v; want ErrIncompatibleUnits at step 1", err)
+	}
+	if _, err := s.ToValueChain(1, "mile"); !errors.Is(err, ErrMissingData) {
+		t.Errorf("ToValueChain(1, mile) error = %v; want ErrMissingData", err)
+	}
+}
+
+func TestToValues1toN(t *testing.T) {
+	s := newDefaultStore(t)
+	got, err := s.ToValues1toN(1, "foot", []string{"inch", "Meter"})
+	if err != nil || !approx(got["inch"], 12) || !approx(got["Meter"], 0.3048) {
+		t.Errorf("ToValues1toN(1, foot) = %v, %v; want 12 inch and 0.3048 Meter", got, err)
+	}
+	if got, err := s.ToValues1toN(1, "foot", []string{"inch", "kilogram"}); !errors.Is(err, ErrIncompatibleUnits) || got != nil {
+		t.Errorf("ToValues1toN(1, foot, kilogram) = %v, %v; want nil, ErrIncompatibleUnits", got, err)
+	}
+	if got, err := s.ToValues1toN(1, "foot", []string{"nosuchunit"}); !errors.Is(err, ErrUnknownUnit) || got != nil {
+		t.Errorf("ToValues1toN(1, foot, nosuchunit) = %v, %v; want nil, ErrUnknownUnit", got, err)
+	}
+}
+
+func TestToValue(t *testing.T) {
+	s := newDefaultStore(t)
+	tests := []struct {
+		val      float64
+		from, to string
+		want     float64
+	}{
+		{1, "inch", "centimeter", 2.54},
+		{1, "mile", "foot", 5280},
+		{100, "Celsius", "Fahrenheit", 212},
+		{0, "Celsius", "Kelvin", 273.15},
+		{1, "pound", "gram", 453.59237},
+		{2, "hour", "minute", 120},
+	}
+	for _, tt := range tests {
+		got, err := s.ToValue(tt.val, tt.from, tt.to)
+		if err != nil || !approx(got, tt.want) {
+			t.Errorf("ToValue(%v, %s, %s) = %v, %v; want %v", tt.val, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+	if _, err := s.ToValue(1, "meter", "kilogram"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("ToValue(1, meter, kilogram) error = %v; want ErrIncompatibleUnits", err)
+	}
+	if _, err := s.ToValue(1, "smoot", "meter"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToValue(1, smoot, meter) error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestToValuePrecise(t *testing.T) {
+	s := newDefaultStore(t)
+	got, lossy, err := s.ToValuePrecise(1, "lightyear", "nanometer")
+	if err != nil || !lossy || !approx(got, 9460730472580800e9) {
+		t.Errorf("ToValuePrecise(1, lightyear, nanometer) = %v, %v, %v; want 9.4607304725808e24, true", got, lossy, err)
+	}
+	got, lossy, err = s.ToValuePrecise(1, "kilometer", "millimeter")
+	if err != nil || lossy || got != 1e6 {
+		t.Errorf("ToValuePrecise(1, kilometer, millimeter) = %v, %v, %v; want 1e6, false", got, lossy, err)
+	}
+	if _, _, err := s.ToValuePrecise(1, "kilometer", "kilogram"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("ToValuePrecise(1, kilometer, kilogram) error = %v; want ErrIncompatibleUnits", err)
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	s := newDefaultStore(t)
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"inch", "kilometer", true},
+		{"Celsius", "Kelvin", true},
+		{"inch", "kilogram", false},
+	}
+	for _, tt := range tests {
+		if got, err := s.Compatible(tt.from, tt.to); err != nil || got != tt.want {
+			t.Errorf("Compatible(%s, %s) = %v, %v; want %v", tt.from, tt.to, got, err, tt.want)
+		}
+	}
+	if _, err := s.Compatible("inch", "smoot"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("Compatible(inch, smoot) error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestUnitsByCategorySorted(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("yard", "yd", "meter", "Length", 0.9144, 0))
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("Inch", "in", "meter", "Length", 0.0254, 0))
+	tests := []struct {
+		by   SortKey
+		want []string
+	}{
+		{SortByName, []string{"Inch", "meter", "yard"}},
+		{SortBySymbol, []string{"Inch", "meter", "yard"}},
+		{SortByFactor, []string{"Inch", "yard", "meter"}},
+		{SortByInsertion, []string{"yard", "meter", "Inch"}},
+	}
+	for _, tt := range tests {
+		var names []string
+		for _, u := range s.UnitsByCategorySorted("Length", tt.by) {
+			names = append(names, u.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("UnitsByCategorySorted(%v) = %v; want %v", tt.by, names, tt.want)
+		}
+	}
+}
+
+func TestConversionTable(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("kilometer", "km", "meter", "Length", 1000, 0))
+	got, err := s.ConversionTable("Length", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"", "kilometer", "meter"},
+		{"kilometer", "2", "2000"},
+		{"meter", "0.002", "2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ConversionTable(Length, 2) = %v; want %v", got, want)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("ConversionTable(Length, 2)[%d] = %v; want %v", i, got[i], want[i])
+		}
+	}
+	if _, err := s.ConversionTable("Mass", 1); !errors.Is(err, ErrMissingData) {
+		t.Errorf("ConversionTable(Mass) error = %v; want ErrMissingData", err)
+	}
+}
+
+func TestRemoveCategory(t *testing.T) {
+	s := newDefaultStore(t)
+	n := len(s.UnitsByCategory("Temperature"))
+	if got := s.RemoveCategory("temperature"); got != n || n == 0 {
+		t.Errorf("RemoveCategory(temperature) = %d; want %d", got, n)
+	}
+	if _, err := s.ToValue(1, "Celsius", "Kelvin"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToValue(Celsius) after RemoveCategory error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestAddFromFilesConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `{"category": "Length", "baseunit": "meter", "units": [
+		{"name": "meter", "factor": 1}, {"name": "foot", "factor": 0.3048}]}`)
+	writeFile(t, dir, "b.json", `{"category": "Length", "baseunit": "meter", "units": [
+		{"name": "inch", "factor": 0.0254}]}`)
+	path := filepath.Join(dir, "*.json")
+
+	s := NewStore()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for range 50 {
+			s.Clear()
+			if err := s.AddFromFiles(LinearReader(), path); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// both files are inserted at once, so inch is never seen
+				// without foot.
+				got, err := s.ToValue(12, "inch", "foot")
+				if err != nil && !errors.Is(err, ErrUnknownUnit) {
+					t.Error(err)
+					return
+				}
+				if err == nil && !approx(got, 1) {
+					t.Errorf("ToValue(12, inch, foot) = %v; want 1", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkAddFromFiles(b *testing.B) {
+	dir := b.TempDir()
+	for i := range 20 {
+		var units []string
+		for j := range 50 {
+			units = append(units, fmt.Sprintf(`{"name": "u%d-%d", "factor": %d}`, i, j, j+1))
+		}
+		data := fmt.Sprintf(`{"category": "C%d", "baseunit": "u%d-0", "units": [%s]}`, i, i, strings.Join(units, ","))
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("c%d.json", i)), []byte(data), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "*.json")
+
+	b.ReportAllocs()
+	for range b.N {
+		s := NewStore()
+		if err := s.AddFromFiles(LinearReader(), path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBestUnit(t *testing.T) {
+	s := newDefaultStore(t)
+	tests := []struct {
+		baseVal float64
+		name    string
+		val     float64
+	}{
+		{1500, "kilometer", 1.5},
+		{0.003, "millimeter", 3},
+	}
+	for _, tt := range tests {
+		u, val, err := s.BestUnit(tt.baseVal, "Distance")
+		if err != nil || u.Name != tt.name || !approx(val, tt.val) {
+			t.Errorf("BestUnit(%v) = %s, %v, %v; want %s, %v", tt.baseVal, u.Name, val, err, tt.name, tt.val)
+		}
+	}
+}
+
+func TestDecodeGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"category": "Length", "baseunit": "meter", "units": [{"name": "meter", "factor": 1}]}`))
+	zw.Close()
+
+	cs, err := LinearReader().Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 || cs[0].Name() != "meter" {
+		t.Errorf("Decode = %v; want meter", cs)
+	}
+}
+
+func TestToValueChecked(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("Kelvin", "K", "Kelvin", "Temperature", 1, 0).WithDomain(Domain{Min: 0, Max: math.Inf(1)}))
+	s.Add(MustLinearConverter("Celsius", "°C", "Kelvin", "Temperature", 1, 273.15))
+	if got, err := s.ToValueChecked(-273, "Celsius", "Kelvin"); err != nil || !approx(got, 0.15) {
+		t.Errorf("ToValueChecked(-273, Celsius, Kelvin) = %v, %v; want 0.15", got, err)
+	}
+	if _, err := s.ToValueChecked(-300, "Celsius", "Kelvin"); !errors.Is(err, ErrOutOfDomain) {
+		t.Errorf("ToValueChecked(-300, Celsius, Kelvin) error = %v; want ErrOutOfDomain", err)
+	}
+}
+
+func TestToValueCheckedPairwise(t *testing.T) {
+	s := NewStore()
+	// two scales with unrelated base UOMs, linked by AddPairwise.
+	s.Add(MustLinearConverter("grade", "", "grade", "Score", 1, 0).WithDomain(Domain{Min: 0, Max: 100}))
+	s.Add(MustLinearConverter("point", "", "point", "Score", 1, 1000))
+	if err := s.AddPairwise("point", "grade", 10); err != nil {
+		t.Fatal(err)
+	}
+	// 5 points are 50 grades, within the domain, although 5 points are 1005
+	// in the base UOM of point.
+	if got, err := s.ToValueChecked(5, "point", "grade"); err != nil || got != 50 {
+		t.Errorf("ToValueChecked(5, point, grade) = %v, %v; want 50", got, err)
+	}
+	if _, err := s.ToValueChecked(20, "point", "grade"); !errors.Is(err, ErrOutOfDomain) {
+		t.Errorf("ToValueChecked(20, point, grade) error = %v; want ErrOutOfDomain", err)
+	}
+}
+
+func TestDecodeArray(t *testing.T) {
+	data := `[{"category": "Length", "baseunit": "meter", "units": [{"name": "meter", "factor": 1}]},
+		{"category": "Mass", "baseunit": "kilogram", "units": [{"name": "kilogram", "factor": 1}]}]`
+	cs, err := LinearReader().Decode(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 || cs[0].Name() != "meter" || cs[1].Name() != "kilogram" {
+		t.Errorf("Decode = %v; want meter and kilogram", cs)
+	}
+}
+
+func TestToRat(t *testing.T) {
+	s := newDefaultStore(t)
+	got, err := s.ToRat(big.NewRat(1, 1), "inch", "millimeter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewRat(254, 10); got.Cmp(want) != 0 {
+		t.Errorf("ToRat(1, inch, millimeter) = %v; want %v", got, want)
+	}
+	if _, err := s.ToRat(nil, "inch", "millimeter"); !errors.Is(err, ErrMissingData) {
+		t.Errorf("ToRat(nil) error = %v; want ErrMissingData", err)
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	s := NewStore()
+	var events []ChangeEvent
+	s.OnChange(func(e ChangeEvent) { events = append(events, e) })
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Remove("meter")
+	s.Clear()
+	want := []ChangeEvent{{ChangeAdd, "meter"}, {ChangeRemove, "meter"}, {ChangeClear, ""}}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %v; want %v", events, want)
+	}
+}
+
+func TestToValueDelta(t *testing.T) {
+	s := newDefaultStore(t)
+	if got, err := s.ToValueDelta(10, "Celsius", "Fahrenheit"); err != nil || !approx(got, 18) {
+		t.Errorf("ToValueDelta(10, Celsius, Fahrenheit) = %v, %v; want 18", got, err)
+	}
+	if got, err := s.ToValueDelta(1, "Kelvin", "Celsius"); err != nil || !approx(got, 1) {
+		t.Errorf("ToValueDelta(1, Kelvin, Celsius) = %v, %v; want 1", got, err)
+	}
+}
+
+func TestToValueDeltaReciprocal(t *testing.T) {
+	s := newFuelStore(t)
+	if _, err := s.ToValueDelta(1, "mpg", "l100km"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("ToValueDelta(1, mpg, l100km) error = %v; want ErrIncompatibleUnits", err)
+	}
+	if _, err := s.ToValueDelta(1, "l100km", "mpg"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("ToValueDelta(1, l100km, mpg) error = %v; want ErrIncompatibleUnits", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := NewStore()
+	a.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	a.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3, 0))
+
+	b := NewStore()
+	b.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0))
+	b.Add(MustLinearConverter("inch", "in", "meter", "Length", 0.0254, 0))
+
+	conflicts := a.Merge(b, MergeError)
+	if len(conflicts) != 1 || !strings.EqualFold(conflicts[0].Name, "foot") {
+		t.Fatalf("Merge(MergeError) conflicts = %v; want foot", conflicts)
+	}
+	if _, ok := a.Get("inch"); ok {
+		t.Error("Merge(MergeError) merged inch despite a conflict")
+	}
+
+	a.Merge(b, MergeKeepExisting)
+	if got, _ := a.ToValue(1, "foot", "meter"); got != 0.3 {
+		t.Errorf("foot after MergeKeepExisting = %v m; want 0.3", got)
+	}
+	a.Merge(b, MergeOverwrite)
+	if got, _ := a.ToValue(1, "foot", "meter"); got != 0.3048 {
+		t.Errorf("foot after MergeOverwrite = %v m; want 0.3048", got)
+	}
+}
+
+func TestCategoriesOrder(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("a", "", "a", "length", 1, 0))
+	s.Add(MustLinearConverter("b", "", "b", "Mass", 1, 0))
+	s.Add(MustLinearConverter("c", "", "c", "Length", 1, 0))
+	s.Add(MustLinearConverter("d", "", "d", "area", 1, 0))
+	// names that differ only in case are listed in byte order.
+	want := []string{"area", "Length", "length", "Mass"}
+	for range 3 {
+		if got := s.Categories(); !slices.Equal(got, want) {
+			t.Fatalf("Categories() = %v; want %v", got, want)
+		}
+	}
+}
+
+func TestCategoriesCacheInvalidation(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("gram", "g", "gram", "Mass", 1, 0))
+	got := s.Categories()
+	got[0] = "changed"
+	if got := s.Categories(); !slices.Equal(got, []string{"Length", "Mass"}) {
+		t.Fatalf("Categories() after changing a returned slice = %v; want [Length Mass]", got)
+	}
+
+	s.Add(MustLinearConverter("second", "s", "second", "Time", 1, 0))
+	if got := s.Categories(); !slices.Equal(got, []string{"Length", "Mass", "Time"}) {
+		t.Errorf("Categories() after Add = %v; want [Length Mass Time]", got)
+	}
+	s.Remove("gram")
+	if got := s.Categories(); !slices.Equal(got, []string{"Length", "Time"}) {
+		t.Errorf("Categories() after Remove = %v; want [Length Time]", got)
+	}
+	s.Add(MustLinearConverter("meter", "m", "meter", "Distance", 1, 0))
+	if got := s.Categories(); !slices.Equal(got, []string{"Distance", "Time"}) {
+		t.Errorf("Categories() after moving meter = %v; want [Distance Time]", got)
+	}
+	s.Clear()
+	if got := s.Categories(); len(got) != 0 {
+		t.Errorf("Categories() after Clear = %v; want none", got)
+	}
+}
+
+func BenchmarkCategories(b *testing.B) {
+	s := newDefaultStore(b)
+	b.ReportAllocs()
+	for range b.N {
+		s.Categories()
+	}
+}
+
+func TestUnitBySymbol(t *testing.T) {
+	s := newDefaultStore(t)
+	if c, ok := s.UnitBySymbol("mm"); !ok || c.Name() != "millimeter" {
+		t.Errorf("UnitBySymbol(mm) = %v, %v; want millimeter", c, ok)
+	}
+	if c, ok := s.UnitBySymbol("Mg"); !ok || c.Name() != "megagram" {
+		t.Errorf("UnitBySymbol(Mg) = %v, %v; want megagram", c, ok)
+	}
+	if _, ok := s.UnitBySymbol("nosuchsymbol"); ok {
+		t.Error("UnitBySymbol(nosuchsymbol) found a unit")
+	}
+}
+
+func TestToValueCacheAgreesWithConvertValue(t *testing.T) {
+	s := newDefaultStore(t)
+	pairs := [][2]string{{"Celsius", "Kelvin"}, {"Fahrenheit", "Celsius"}, {"inch", "mile"}, {"pound", "gram"}}
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 10000 {
+		p := pairs[r.IntN(len(pairs))]
+		val := (r.Float64() - 0.5) * math.Pow(10, float64(r.IntN(12)))
+		f, _ := s.Get(p[0])
+		to, _ := s.Get(p[1])
+		want, werr := ConvertValue(val, f, to)
+		// the first call fills the cache, the second uses it.
+		for range 2 {
+			got, err := s.ToValue(val, p[0], p[1])
+			if got != want || (err == nil) != (werr == nil) {
+				t.Fatalf("ToValue(%v, %s, %s) = %v, %v; ConvertValue = %v, %v", val, p[0], p[1], got, err, want, werr)
+			}
+		}
+	}
+}
+
+func TestToValueCacheInvalidation(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3, 0))
+	if got, _ := s.ToValue(10, "foot", "meter"); got != 3 {
+		t.Fatalf("ToValue(10, foot, meter) = %v; want 3", got)
+	}
+	if err := s.UpdateFactor("foot", 0.3048, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ToValue(10, "foot", "meter"); !approx(got, 3.048) {
+		t.Errorf("ToValue(10, foot, meter) after UpdateFactor = %v; want 3.048", got)
+	}
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.5, 0))
+	if got, _ := s.ToValue(10, "foot", "meter"); got != 5 {
+		t.Errorf("ToValue(10, foot, meter) after Add = %v; want 5", got)
+	}
+	s.Remove("foot")
+	if _, err := s.ToValue(10, "foot", "meter"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToValue(10, foot, meter) after Remove error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestToValueCacheIsBounded(t *testing.T) {
+	s := NewStore()
+	var names []string
+	for i := range 50 {
+		name := fmt.Sprintf("u%d", i)
+		names = append(names, name)
+		s.Add(MustLinearConverter(name, "", "u0", "X", float64(i+1), 0))
+	}
+	for _, from := range names {
+		for _, to := range names {
+			if _, err := s.ToValue(1, from, to); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if n := len(s.linear); n > maxCachedPairs {
+		t.Errorf("cache holds %d pairs; want at most %d", n, maxCachedPairs)
+	}
+}
+
+func BenchmarkToValue(b *testing.B) {
+	s := NewStore()
+	s.Add(MustLinearConverter("Fahrenheit", "°F", "Fahrenheit", "Temperature", 1, 0))
+	s.Add(MustLinearConverter("Celsius", "°C", "Fahrenheit", "Temperature", 1.8, 32))
+	for range b.N {
+		s.ToValue(100, "Celsius", "Fahrenheit")
+	}
+}
+
+func BenchmarkToValueUncached(b *testing.B) {
+	s := NewStore()
+	s.Add(MustLinearConverter("Fahrenheit", "°F", "Fahrenheit", "Temperature", 1, 0))
+	s.Add(MustLinearConverter("Celsius", "°C", "Fahrenheit", "Temperature", 1.8, 32))
+	for range b.N {
+		// a change clears the cache, as after every update of the store.
+		s.mu.Lock()
+		s.invalidate()
+		s.mu.Unlock()
+		s.ToValue(100, "Celsius", "Fahrenheit")
+	}
+}
+
+func TestAddFromDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `{"category": "Length", "baseunit": "meter", "units": [{"name": "meter", "factor": 1}]}`)
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub"), "b.json", `{"category": "Length", "baseunit": "meter", "units": [{"name": "foot", "factor": 0.3048}]}`)
+	writeFile(t, dir, "notes.txt", "not units")
+
+	s := NewStore()
+	if err := s.AddFromDir(LinearReader(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "foot", "meter"); err != nil || got != 0.3048 {
+		t.Errorf("ToValue(1, foot, meter) = %v, %v; want 0.3048", got, err)
+	}
+}
+
+func TestDeriveAreaVolume(t *testing.T) {
+	s := newDefaultStore(t)
+	if err := s.DeriveAreaVolume("foot"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "foot cubed", "cubic meter"); err != nil || !approx(got, 0.3048*0.3048*0.3048) {
+		t.Errorf("ToValue(1, foot cubed, cubic meter) = %v, %v", got, err)
+	}
+	c, ok := s.Get("foot squared")
+	if !ok {
+		t.Fatal("foot squared is not registered")
+	}
+	if c.Symbol() != "ft²" {
+		t.Errorf("symbol of foot squared = %q; want ft²", c.Symbol())
+	}
+}
+
+func TestAddFromFilesExcluding(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "length.json", `{"category": "Length", "baseunit": "meter", "units": [{"name": "meter", "factor": 1}]}`)
+	writeFile(t, dir, "length.test.json", `{"category": "Length", "baseunit": "meter", "units": [{"name": "smoot", "factor": 1.7018}]}`)
+	writeFile(t, dir, "mass.json", `{"category": "Mass", "baseunit": "kilogram", "units": [{"name": "kilogram", "factor": 1}]}`)
+
+	s := NewStore()
+	err := s.AddFromFilesExcluding(LinearReader(), filepath.Join(dir, "*.json"), []string{"*.test.json", filepath.Join(dir, "mass.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("meter"); !ok {
+		t.Error("meter is not in the store")
+	}
+	if _, ok := s.Get("smoot"); ok {
+		t.Error("smoot, excluded by base name, is in the store")
+	}
+	if _, ok := s.Get("kilogram"); ok {
+		t.Error("kilogram, excluded by path, is in the store")
+	}
+	if err := s.AddFromFilesExcluding(LinearReader(), filepath.Join(dir, "*.json"), []string{"["}); !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("AddFromFilesExcluding with a malformed pattern error = %v; want filepath.ErrBadPattern", err)
+	}
+}
+
+func TestFilterConverters(t *testing.T) {
+	s := newFuelStore(t)
+	s.Add(MustLinearConverter("km/l", "km/L", "l100km", "Fuel", 1, 0))
+	got := s.FilterConverters(func(c Converter) bool {
+		_, ok := c.(linearConverter)
+		return ok
+	})
+	var names []string
+	for _, c := range got {
+		names = append(names, c.Name())
+	}
+	if want := []string{"km/l", "l100km"}; !slices.Equal(names, want) {
+		t.Errorf("FilterConverters(linear) = %v; want %v", names, want)
+	}
+	if got := s.FilterConverters(func(Converter) bool { return false }); len(got) != 0 {
+		t.Errorf("FilterConverters(none) = %v; want none", got)
+	}
+}
+
+func TestLinearConverterRelative(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("Kelvin", "K", "Kelvin", "Temperature", 1, 0))
+	c, err := s.LinearConverterRelative("Celsius", "°C", "Kelvin", 1, 273.15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(c)
+	// °F = °C*9/5 + 32, so °C = °F*5/9 - 160/9.
+	f, err := s.LinearConverterRelative("Fahrenheit", "°F", "Celsius", 5.0/9, -160.0/9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(f)
+	if f.Category() != "Temperature" || f.BaseUOM() != "Kelvin" {
+		t.Errorf("Fahrenheit is in %s with base %s; want Temperature and Kelvin", f.Category(), f.BaseUOM())
+	}
+	if got, err := s.ToValue(212, "Fahrenheit", "Kelvin"); err != nil || !approx(got, 373.15) {
+		t.Errorf("ToValue(212, Fahrenheit, Kelvin) = %v, %v; want 373.15", got, err)
+	}
+	if _, err := s.LinearConverterRelative("x", "", "Rankine", 1, 0); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("LinearConverterRelative(Rankine) error = %v; want ErrUnknownUnit", err)
+	}
+	mpg, err := ReciprocalConverter("mpg", "mpg", "l100km", "Fuel", 235.215)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(mpg)
+	if _, err := s.LinearConverterRelative("x", "", "mpg", 1, 0); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("LinearConverterRelative(mpg) error = %v; want ErrIncompatibleUnits", err)
+	}
+}
+
+func TestConvertRange(t *testing.T) {
+	s := newDefaultStore(t)
+	low, high, err := s.ConvertRange("-40 to 100", "Celsius", "Fahrenheit")
+	if err != nil || !approx(low, -40) || !approx(high, 212) {
+		t.Errorf("ConvertRange(-40 to 100) = %v, %v, %v; want -40, 212", low, high, err)
+	}
+	low, high, err = s.ConvertRange("1-2", "kilometer", "meter")
+	if err != nil || low != 1000 || high != 2000 {
+		t.Errorf("ConvertRange(1-2) = %v, %v, %v; want 1000, 2000", low, high, err)
+	}
+	if _, _, err := s.ConvertRange("12", "kilometer", "meter"); !errors.Is(err, ErrMalformedData) {
+		t.Errorf("ConvertRange(12) error = %v; want ErrMalformedData", err)
+	}
+}
+
+func TestToValueWith(t *testing.T) {
+	s := newDefaultStore(t)
+	yard := MustLinearConverter("yard", "yd", "meter", "Distance", 1, 0)
+	got, err := s.ToValueWith(3, "YARD", "meter", map[string]Converter{"Yard": yard})
+	if err != nil || got != 3 {
+		t.Errorf("ToValueWith(3, YARD, meter) = %v, %v; want 3", got, err)
+	}
+	if got, _ := s.ToValue(1, "yard", "meter"); !approx(got, 0.9144) {
+		t.Errorf("ToValue(1, yard, meter) = %v after ToValueWith; want 0.9144", got)
+	}
+}
+
+func TestToValueSigFigs(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	tests := []struct{ val, want float64 }{
+		{0.00123456, 0.00123},
+		{123456, 123000},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got, err := s.ToValueSigFigs(tt.val, "meter", "meter", 3); err != nil || got != tt.want {
+			t.Errorf("ToValueSigFigs(%v, 3) = %v, %v; want %v", tt.val, got, err, tt.want)
+		}
+	}
+	if _, err := s.ToValueSigFigs(1, "meter", "meter", 0); !errors.Is(err, ErrMalformedData) {
+		t.Errorf("ToValueSigFigs(sig 0) error = %v; want ErrMalformedData", err)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3, 0))
+	s.Add(MustLinearConverter("yard", "yd", "meter", "Length", 0.9144, 0))
+	old := s.Snapshot()
+
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0))
+	s.Remove("yard")
+	s.Add(MustLinearConverter("inch", "in", "meter", "Length", 0.0254, 0))
+	if len(old) != 3 {
+		t.Errorf("Snapshot changed with the store: %d units; want 3", len(old))
+	}
+
+	d := Diff(old, s.Snapshot())
+	want := StoreDiff{Added: []string{"inch"}, Removed: []string{"yard"}, Changed: []string{"foot"}}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("Diff = %+v; want %+v", d, want)
+	}
+	if d := Diff(old, old); len(d.Added)+len(d.Removed)+len(d.Changed) != 0 {
+		t.Errorf("Diff of a snapshot with itself = %+v; want no differences", d)
+	}
+}
+
+func TestParseAndConvertLocale(t *testing.T) {
+	s := newDefaultStore(t)
+	tests := []struct {
+		input string
+		sep   rune
+		want  float64
+	}{
+		{"10,5 km", ',', 10500},
+		{"1 000,5 km", ',', 1000500},
+		{"1.000,5 km", ',', 1000500},
+		{"1,000.5 km", '.', 1000500},
+	}
+	for _, tt := range tests {
+		if got, err := s.ParseAndConvertLocale(tt.input, "meter", tt.sep); err != nil || !approx(got, tt.want) {
+			t.Errorf("ParseAndConvertLocale(%q, %q) = %v, %v; want %v", tt.input, tt.sep, got, err, tt.want)
+		}
+	}
+}
+
+func TestWithConverters(t *testing.T) {
+	s := newDefaultStore(t)
+	foot := MustLinearConverter("foot", "ft", "meter", "Distance", 0.3, 0)
+	s.WithConverters([]Converter{foot}, func() {
+		if got, _ := s.ToValue(1, "foot", "meter"); got != 0.3 {
+			t.Errorf("ToValue(1, foot, meter) in scope = %v; want 0.3", got)
+		}
+		s.Remove("inch")
+	})
+	if got, _ := s.ToValue(1, "foot", "meter"); got != 0.3048 {
+		t.Errorf("ToValue(1, foot, meter) after scope = %v; want 0.3048", got)
+	}
+	if _, ok := s.Get("inch"); !ok {
+		t.Error("inch removed in scope is not restored")
+	}
+}
+
+func TestWithConvertersEvents(t *testing.T) {
+	s := NewStore()
+	for _, c := range []Converter{
+		MustLinearConverter("meter", "m", "meter", "Length", 1, 0),
+		MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0),
+		MustLinearConverter("inch", "in", "meter", "Length", 0.0254, 0),
+		MustLinearConverter("yard", "yd", "meter", "Length", 0.9144, 0),
+	} {
+		s.Add(c)
+	}
+	var events []ChangeEvent
+	s.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	foot := MustLinearConverter("foot", "ft", "meter", "Length", 0.3, 0)
+	s.WithConverters([]Converter{foot}, func() {
+		s.Remove("inch")
+		s.Add(MustLinearConverter("mile", "mi", "meter", "Length", 1609.344, 0))
+		s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+		events = nil
+	})
+
+	slices.SortFunc(events, func(a, b ChangeEvent) int { return strings.Compare(a.Name, b.Name) })
+	want := []ChangeEvent{{ChangeAdd, "foot"}, {ChangeAdd, "inch"}, {ChangeRemove, "mile"}}
+	if !slices.Equal(events, want) {
+		t.Errorf("events when restoring = %v; want %v", events, want)
+	}
+}
+
+func TestConvertWithDensity(t *testing.T) {
+	s := newDefaultStore(t)
+	if got, err := s.ConvertWithDensity(200, "gram", "cubic meter", 1000); err != nil || !approx(got, 0.0002) {
+		t.Errorf("ConvertWithDensity(200 g of water) = %v, %v; want 0.0002 m³", got, err)
+	}
+	if got, err := s.ConvertWithDensity(0.0002, "cubic meter", "gram", 1000); err != nil || !approx(got, 200) {
+		t.Errorf("ConvertWithDensity(0.0002 m³ of water) = %v, %v; want 200 g", got, err)
+	}
+	if _, err := s.ConvertWithDensity(1, "gram", "kilogram", 1000); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("ConvertWithDensity(gram, kilogram) error = %v; want ErrIncompatibleUnits", err)
+	}
+	if _, err := s.ConvertWithDensity(1, "gram", "cubic meter", 0); !errors.Is(err, ErrMalformedData) {
+		t.Errorf("ConvertWithDensity(density 0) error = %v; want ErrMalformedData", err)
+	}
+}
+
+func TestFuncConverterValidity(t *testing.T) {
+	kelvin := MustLinearConverter("Kelvin", "K", "Kelvin", "Temperature", 1, 0)
+	celsius, err := FuncConverter("Celsius", "°C", "Kelvin", "Temperature",
+		func(v float64) float64 { return v + 273.15 },
+		func(v float64) float64 { return v - 273.15 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	celsius = celsius.WithValidity(func(base float64) bool { return base >= 0 })
+
+	s := NewStore()
+	s.Add(kelvin)
+	s.Add(celsius)
+	if got, err := s.ToValue(0, "Celsius", "Kelvin"); err != nil || !approx(got, 273.15) {
+		t.Errorf("ToValue(0, Celsius, Kelvin) = %v, %v; want 273.15", got, err)
+	}
+	if _, err := s.ToValue(-300, "Celsius", "Kelvin"); !errors.Is(err, ErrOutOfDomain) {
+		t.Errorf("ToValue(-300, Celsius, Kelvin) error = %v; want ErrOutOfDomain", err)
+	}
+	if _, err := FuncConverter("x", "", "Kelvin", "Temperature", nil, nil); !errors.Is(err, ErrMissingData) {
+		t.Errorf("FuncConverter without functions error = %v; want ErrMissingData", err)
+	}
+}
+
+func TestLookupNormalizer(t *testing.T) {
+	defer func(n Normalizer) { LookupNormalizer = n }(LookupNormalizer)
+	LookupNormalizer = NormalizerFunc(func(name string) string {
+		return strings.TrimSuffix(strings.ToLower(name), "s")
+	})
+
+	s := NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	s.Add(MustLinearConverter("foot", "ft", "meter", "Length", 0.3048, 0))
+	if got, err := s.ToValue(2, "Meters", "foot"); err != nil || !approx(got, 6.561679790026247) {
+		t.Errorf("ToValue(2, Meters, foot) = %v, %v; want 6.5617", got, err)
+	}
+	if _, ok := s.Get("feet"); ok {
+		t.Error("Get(feet) found a unit; the normalizer only strips a trailing s")
+	}
+
+	LookupNormalizer = NormalizerFunc(func(name string) string { return name })
+	s = NewStore()
+	s.Add(MustLinearConverter("meter", "m", "meter", "Length", 1, 0))
+	if _, ok := s.Get("Meter"); ok {
+		t.Error("Get(Meter) found meter with a case-sensitive normalizer")
+	}
+}
+
+func TestAddFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"units/length.json": {Data: []byte(`{"category": "Length", "baseunit": "meter", "units": [
+			{"name": "meter", "symbol": "m", "factor": 1}, {"name": "foot", "symbol": "ft", "factor": 0.3048}]}`)},
+	}
+	s := NewStore()
+	if err := s.AddFromFS(fsys, LinearReader(), "units/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "foot", "meter"); err != nil || got != 0.3048 {
+		t.Errorf("ToValue(1, foot, meter) = %v, %v; want 0.3048", got, err)
+	}
+	if src, _ := s.Source("foot"); src != "units/length.json" {
+		t.Errorf("Source(foot) = %q; want units/length.json", src)
+	}
+}
+
+func TestRename(t *testing.T) {
+	s := newDefaultStore(t)
+	if err := s.Rename("foot", "feet"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("foot"); ok {
+		t.Error("foot is still registered after Rename")
+	}
+	if got, err := s.ToValue(1, "feet", "inch"); err != nil || !approx(got, 12) {
+		t.Errorf("ToValue(1, feet, inch) = %v, %v; want 12", got, err)
+	}
+	if src, ok := s.Source("feet"); !ok || src != "data/distance.json" {
+		t.Errorf("Source(feet) = %q, %v; want data/distance.json", src, ok)
+	}
+	if err := s.Rename("smoot", "smoots"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("Rename(smoot) error = %v; want ErrUnknownUnit", err)
+	}
+	if err := s.Rename("inch", "kilogram"); !errors.Is(err, ErrDuplicateUnit) {
+		t.Errorf("Rename(inch, kilogram) error = %v; want ErrDuplicateUnit", err)
+	}
+}
+
+func TestRenameThenSetRate(t *testing.T) {
+	dollar, _ := RateConverter("dollar", "$", "dollar", "Money", 1)
+	euro, _ := RateConverter("euro", "€", "dollar", "Money", 1.25)
+	s := NewStore()
+	s.RegisterAll([]Converter{dollar, euro})
+	if err := s.Rename("euro", "EUR"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetRate("EUR", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(2, "EUR", "dollar"); err != nil || !approx(got, 3) {
+		t.Errorf("ToValue(2, EUR, dollar) after SetRate = %v, %v; want 3", got, err)
+	}
+	if err := s.SetRate("euro", 2); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("SetRate(euro) after Rename error = %v; want ErrUnknownUnit", err)
+	}
+}
+
+func TestSetMaxUnits(t *testing.T) {
+	s := NewStore()
+	s.SetMaxUnits(2)
+	s.Add(MustLinearConverter("a", "", "a", "X", 1, 0))
+	s.Add(MustLinearConverter("b", "", "a", "X", 2, 0))
+	if err := s.Add(MustLinearConverter("c", "", "a", "X", 3, 0)); !errors.Is(err, ErrStoreFull) {
+		t.Errorf("Add to a full store error = %v; want ErrStoreFull", err)
+	}
+	if err := s.Add(MustLinearConverter("b", "", "a", "X", 4, 0)); err != nil {
+		t.Errorf("replacing a unit in a full store: %v", err)
+	}
+
+	s.SetOverflowPolicy(EvictOldest)
+	if err := s.Add(MustLinearConverter("c", "", "a", "X", 3, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("a"); ok {
+		t.Error("a is still registered; want it evicted as the oldest unit")
+	}
+	if _, ok := s.Get("c"); !ok {
+		t.Error("c is not registered")
+	}
+}
+
+func TestNormalizeTo(t *testing.T) {
+	s := newDefaultStore(t)
+	got, err := s.NormalizeTo([]float64{1, 100, 1}, []string{"meter", "centimeter", "kilometer"}, "meter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1, 1, 1000}; !slices.Equal(got, want) {
+		t.Errorf("NormalizeTo = %v; want %v", got, want)
+	}
+	if _, err := s.NormalizeTo([]float64{1}, nil, "meter"); !errors.Is(err, ErrMalformedData) {
+		t.Errorf("NormalizeTo with mismatched lengths error = %v; want ErrMalformedData", err)
+	}
+	got, err = s.NormalizeTo([]float64{1, 1}, []string{"meter", "smoot"}, "meter")
+	if !errors.Is(err, ErrUnknownUnit) || got != nil {
+		t.Errorf("NormalizeTo with unknown unit = %v, %v; want nil, ErrUnknownUnit", got, err)
+	}
+}
+
+func TestRegisterDerived(t *testing.T) {
+	s := NewStore()
+	s.Add(MustLinearConverter("inch", "in", "meter", "Length", 0.0254, 0))
+	err := s.RegisterDerived("inch", []DerivedSpec{{Name: "foot", Symbol: "ft", Factor: 12}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ToValue(1, "foot", "inch"); err != nil || !approx(got, 12) {
+		t.Errorf("ToValue(1, foot, inch) = %v, %v; want 12", got, err)
+	}
+	if err := s.RegisterDerived("smoot", []DerivedSpec{{Name: "x", Factor: 1}}); err == nil {
+		t.Error("RegisterDerived(smoot) succeeded; want an error")
+	}
+}
@@ -0,0 +1,78 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// A KeyStyle selects how the keys of a JSON response are written.
+type KeyStyle int
+
+const (
+	KeyLower KeyStyle = iota // lowercase without separators, e.g. "fromsymbol", as written by ToJson.
+	KeySnake                 // snake_case, e.g. "from_symbol".
+	KeyCamel                 // camelCase, e.g. "fromSymbol" and "baseUOM".
+)
+
+// styledKeys maps the response keys made of several words to their snake_case
+// and camelCase renderings. Keys of a single word are the same in all styles.
+var styledKeys = map[string][2]string{
+	"roundedresult": {"rounded_result", "roundedResult"},
+	"fromsymbol":    {"from_symbol", "fromSymbol"},
+	"tosymbol":      {"to_symbol", "toSymbol"},
+	"baseuom":       {"base_uom", "baseUOM"},
+}
+
+// ToJsonStyled converts val from the unit specified by from to the unit
+// specified by to and returns the same response as ToJson with its keys
+// written in style.
+func ToJsonStyled(val float64, from, to string, style KeyStyle) ([]byte, error) {
+	return store.ToJsonStyled(val, from, to, style)
+}
+
+// ToJsonStyled converts val from the unit specified by from to the unit
+// specified by to and returns the same response as ToJson with its keys
+// written in style.
+func (s *Store) ToJsonStyled(val float64, from, to string, style KeyStyle) ([]byte, error) {
+	return s.ToJsonWith(val, from, to, JsonOptions{KeyStyle: style})
+}
+
+// restyle rewrites the keys of b, a flat JSON object as written for a
+// response, in style, keeping their order.
+func restyle(b []byte, style KeyStyle) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; dec.More(); i++ {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+
+		k := key.(string)
+		if styled, ok := styledKeys[k]; ok {
+			k = styled[style-KeySnake]
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,74 @@
+package convert
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Suggest returns up to max names of units in the store that are close to
+// name, for "did you mean" hints after ErrUnknownUnit. A unit is close if its
+// name starts with name or is within a Levenshtein distance of about half the
+// length of name, ignoring case. The names are sorted by distance and then by
+// name.
+func Suggest(name string, max int) []string {
+	return store.Suggest(name, max)
+}
+
+// Suggest returns up to max names of units in the store that are close to
+// name. See the package-level Suggest for details.
+func (s *Store) Suggest(name string, max int) []string {
+	if max <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		name string
+		dist int
+	}
+
+	name = unitKey(name)
+	limit := len([]rune(name))/2 + 1
+
+	s.mu.RLock()
+	var candidates []candidate
+	for key, c := range s.data {
+		d := levenshtein(name, key)
+		if d <= limit || strings.HasPrefix(key, name) {
+			candidates = append(candidates, candidate{c.Name(), d})
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(a.dist, b.dist), strings.Compare(a.name, b.name))
+	})
+
+	names := make([]string, 0, min(max, len(candidates)))
+	for _, c := range candidates[:min(max, len(candidates))] {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// levenshtein returns the Levenshtein edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,84 @@
+package convert
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalize returns s in Unicode normalization form NFKC, with the micro sign
+// U+00B5 replaced by the Greek small letter mu U+03BC. Unit names and symbols
+// typed or copied from different sources then compare equal, e.g. "µm" with
+// either character, "Ω" as the ohm sign or the Greek capital omega, and "m²"
+// and "m2". Surrounding whitespace is trimmed and internal runs of whitespace
+// are collapsed to a single space, so " Nautical  Mile " matches
+// "nautical mile" once lowercased.
+func normalize(s string) string {
+	s = strings.Join(strings.Fields(norm.NFKC.String(s)), " ")
+	return strings.ReplaceAll(s, "µ", "μ")
+}
+
+// A Normalizer maps unit names to the keys under which units are stored and
+// looked up, so that names with the same key refer to the same unit.
+type Normalizer interface {
+	Normalize(name string) string
+}
+
+// NormalizerFunc adapts a function to a Normalizer.
+type NormalizerFunc func(name string) string
+
+// Normalize returns f(name).
+func (f NormalizerFunc) Normalize(name string) string {
+	return f(name)
+}
+
+// LookupNormalizer defines which unit names match: it is applied, after
+// Unicode normalization, to the names of units when they are added to a store
+// and to the names they are looked up by. It defaults to case folding with
+// strings.ToLower and may be replaced, e.g. to strip diacritics or plural
+// endings. As the keys of the units in a store are computed when they are
+// added, it must be set before any unit is added and must not be changed
+// afterwards.
+var LookupNormalizer Normalizer = NormalizerFunc(strings.ToLower)
+
+// unitKey returns the key under which the unit called name is stored.
+func unitKey(name string) string {
+	return LookupNormalizer.Normalize(normalize(name))
+}
+
+// UnitBySymbol returns the Converter whose symbol matches symbol after Unicode
+// normalization. Symbols are case-sensitive, so "Mm" and "mm" are different
+// units. If several units share the symbol, the one whose name sorts first is
+// returned. It returns false if no unit has the symbol.
+func UnitBySymbol(symbol string) (Converter, bool) {
+	return store.UnitBySymbol(symbol)
+}
+
+// UnitBySymbol returns the Converter whose symbol matches symbol. See the
+// package-level UnitBySymbol for details.
+func (s *Store) UnitBySymbol(symbol string) (Converter, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := s.symbols[normalize(symbol)]
+	if len(keys) == 0 {
+		return nil, false
+	}
+	first := ""
+	for key := range keys {
+		if first == "" || key < first {
+			first = key
+		}
+	}
+	return s.data[first], true
+}
+
+// dropSymbol removes c, stored under key, from the symbol index of the store.
+// The caller must hold the write lock.
+func (s *Store) dropSymbol(c Converter, key string) {
+	sym := normalize(c.Symbol())
+	delete(s.symbols[sym], key)
+	if len(s.symbols[sym]) == 0 {
+		delete(s.symbols, sym)
+	}
+}
@@ -0,0 +1,45 @@
+package convert
+
+import "strconv"
+
+// ConversionTable returns a grid showing what val of each unit in category is
+// in every other unit of the category, suitable for rendering as CSV or
+// Markdown. The first row holds an empty cell followed by the unit names; each
+// following row holds the name of a unit followed by val of that unit
+// converted to the unit of each column. Units are in the order of
+// UnitsByCategory. The table makes n² conversions for n units, so it is best
+// kept to small categories.
+func ConversionTable(category string, val float64) ([][]string, error) {
+	return store.ConversionTable(category, val)
+}
+
+// ConversionTable returns a grid showing what val of each unit in category is
+// in every other unit of the category. See the package-level ConversionTable
+// for details.
+func (s *Store) ConversionTable(category string, val float64) ([][]string, error) {
+	units := s.UnitsByCategory(category)
+	if len(units) == 0 {
+		return nil, Error(ErrMissingData, "no units in category "+category)
+	}
+
+	header := make([]string, 0, len(units)+1)
+	header = append(header, "")
+	for _, u := range units {
+		header = append(header, u.Name)
+	}
+
+	table := [][]string{header}
+	for _, from := range units {
+		row := make([]string, 0, len(units)+1)
+		row = append(row, from.Name)
+		for _, to := range units {
+			v, err := s.ToValue(val, from.Name, to.Name)
+			if err != nil {
+				return nil, Error(err, from.Name+" -> "+to.Name)
+			}
+			row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		table = append(table, row)
+	}
+	return table, nil
+}
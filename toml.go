@@ -0,0 +1,53 @@
+package convert
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlLayout reads Converter data for linear UOMs from toml files. The files
+// use the same schema as the json files read by LinearReader:
+//
+//	category = "Length"
+//	baseunit = "meter"
+//
+//	[[units]]
+//	name = "inch"
+//	symbol = "in"
+//	factor = 0.0254
+type tomlLayout struct {
+	layout fileLayout
+}
+
+// TOMLReader returns a new instance of tomlLayout that can be used to read
+// linear uom data from a toml file.
+func TOMLReader() *tomlLayout {
+	return new(tomlLayout)
+}
+
+// ReadFile reads the linear UOMs in filename and returns them as Converters. A
+// missing offset defaults to 0 and a missing factor is rejected with
+// ErrZeroNotAllowed.
+func (tl *tomlLayout) ReadFile(filename string) ([]Converter, error) {
+	tl.layout = fileLayout{}
+	if _, err := toml.DecodeFile(filename, &tl.layout); err != nil {
+		return nil, err
+	}
+	return tl.layout.converters()
+}
+
+// Decode reads the linear UOMs from r and returns them as Converters. See
+// ReadFile for details.
+func (tl *tomlLayout) Decode(r io.Reader) ([]Converter, error) {
+	tl.layout = fileLayout{}
+	if _, err := toml.NewDecoder(r).Decode(&tl.layout); err != nil {
+		return nil, err
+	}
+	return tl.layout.converters()
+}
+
+// Extensions returns the file extensions read by a tomlLayout.
+func (tl *tomlLayout) Extensions() []string {
+	return []string{".toml"}
+}